@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+)
+
+// parseClip parses the -start and -end flags; empty flags are zero
+func parseClip(start, end string) (time.Duration, time.Duration, error) {
+	var from, to time.Duration
+	var err error
+	if start != "" {
+		if from, err = transcript.ParseTimestamp(start); err != nil {
+			return 0, 0, fmt.Errorf("-start: %w", err)
+		}
+	}
+	if end != "" {
+		if to, err = transcript.ParseTimestamp(end); err != nil {
+			return 0, 0, fmt.Errorf("-end: %w", err)
+		}
+		if to <= from {
+			return 0, 0, fmt.Errorf("-end %s must come after -start %s", end, transcript.Timestamp(from))
+		}
+	}
+	return from, to, nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClip(t *testing.T) {
+	start, end, err := parseClip("40m", "1:10:00")
+	if err != nil || start != 40*time.Minute || end != 70*time.Minute {
+		t.Errorf("parseClip() = %v, %v, %v", start, end, err)
+	}
+	if start, end, err := parseClip("", ""); err != nil || start != 0 || end != 0 {
+		t.Errorf("parseClip() without flags = %v, %v, %v", start, end, err)
+	}
+	for _, tt := range [][2]string{{"soon", ""}, {"", "later"}, {"70m", "40m"}, {"", "0"}} {
+		if _, _, err := parseClip(tt[0], tt[1]); err == nil {
+			t.Errorf("parseClip(%q, %q) succeeded, want error", tt[0], tt[1])
+		}
+	}
+}
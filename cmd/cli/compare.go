@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// comparePane is one model's column in compare mode
+type comparePane struct {
+	comparison core.Comparison
+	viewport   viewport.Model
+}
+
+type compareMsg struct {
+	comparisons []core.Comparison
+}
+
+func fetchComparison(url string, models []string, opts options) tea.Cmd {
+	return func() tea.Msg {
+		comparisons := core.Compare(context.Background(), core.Request{URL: url, Start: opts.start, End: opts.end}, models)
+		for _, c := range comparisons {
+			if c.Err == nil {
+				// Failing to persist shouldn't hide the comparison itself
+				saveSummary(&store.Summary{URL: url, Model: c.Model, Content: c.Result.Text})
+			}
+		}
+		return compareMsg{comparisons: comparisons}
+	}
+}
+
+func newComparePanes(comparisons []core.Comparison, width, height int) []comparePane {
+	panes := make([]comparePane, len(comparisons))
+	for i, c := range comparisons {
+		panes[i] = comparePane{comparison: c, viewport: viewport.New(0, 0)}
+	}
+	layoutPanes(panes, width, height)
+	return panes
+}
+
+// layoutPanes splits the terminal width evenly and re-renders each pane's Markdown
+func layoutPanes(panes []comparePane, width, height int) {
+	if len(panes) == 0 {
+		return
+	}
+	paneWidth := width/len(panes) - 2
+	for i := range panes {
+		p := &panes[i]
+		p.viewport.Width = paneWidth
+		p.viewport.Height = height - 9
+
+		content := fmt.Sprintf("Error: %v", p.comparison.Err)
+		if p.comparison.Err == nil {
+			content = p.comparison.Result.Text
+		}
+		renderer, _ := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(paneWidth-4),
+		)
+		if rendered, err := renderer.Render(content); err == nil {
+			content = rendered
+		}
+		p.viewport.SetContent(content)
+	}
+}
+
+func (m model) compareView() string {
+	columns := make([]string, len(m.panes))
+	for i, p := range m.panes {
+		border := lipgloss.Color("240")
+		if i == m.focus {
+			border = lipgloss.Color("62")
+		}
+
+		header := lipgloss.NewStyle().Bold(true).Render(p.comparison.Model)
+		stats := "failed"
+		if r := p.comparison.Result; r != nil {
+			stats = fmt.Sprintf("%s · %d in / %d out tokens",
+				r.Latency.Round(100*time.Millisecond), r.Usage.PromptTokens, r.Usage.OutputTokens)
+		}
+		stats = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262")).Render(stats)
+
+		columns[i] = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(border).
+			Width(p.viewport.Width).
+			Render(header + "\n" + stats + "\n" + p.viewport.View())
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// splitList parses a comma separated flag value
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+// configure resolves the configuration for parsed flags and applies it to core
+func configure(f *config.Flags) error {
+	cfg, err := f.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return core.Configure(cfg)
+}
+
+// runConfig implements `ytsum config show [flags]`
+func runConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(stderr, "Usage: ytsum config show [flags]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cfgFlags := config.RegisterFlags(fs, false)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+	if err := config.Show(stdout, cfg); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
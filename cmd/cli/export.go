@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+// runExport implements `ytsum export [-format md,pdf] [-out dir] <id>`
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	formats := fs.String("format", "md", "comma separated export formats ("+formatList()+")")
+	dir := fs.String("out", ".", "directory to write exported files to")
+	cfgFlags := config.RegisterFlags(fs, false)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ytsum export [-format md,pdf] [-out dir] <id>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid summary id %q\n", fs.Arg(0))
+		return 2
+	}
+	selected, err := parseFormats(*formats)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	s, err := core.OpenStore()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening summary store: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	summary, err := s.Get(context.Background(), id, "")
+	if errors.Is(err, store.ErrNotFound) {
+		fmt.Fprintf(stderr, "No summary with id %d\n", id)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading summary: %v\n", err)
+		return 1
+	}
+
+	status := 0
+	for _, f := range selected {
+		path, err := exportSummary(summary, f, *dir)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error exporting %s: %v\n", f, err)
+			status = 1
+			continue
+		}
+		fmt.Fprintln(stdout, path)
+	}
+	return status
+}
+
+// exportSummary writes summary to dir in format f and returns the file path
+func exportSummary(summary *store.Summary, f export.Format, dir string) (string, error) {
+	if summary.Title == "" {
+		summary.Title = store.TitleFromMarkdown(summary.Content, summary.URL)
+	}
+	if summary.CreatedAt.IsZero() {
+		summary.CreatedAt = time.Now().UTC()
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, export.Filename(summary, f))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := export.Write(file, f, summary); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// parseFormats parses a comma separated list of export formats
+func parseFormats(list string) ([]export.Format, error) {
+	var formats []export.Format
+	for _, name := range strings.Split(list, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		f, err := export.ParseFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+func formatList() string {
+	var names []string
+	for _, f := range export.Formats() {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
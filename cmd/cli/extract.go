@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+	"github.com/charmbracelet/glamour"
+)
+
+// runExtract implements `ytsum extract [-format table|csv|json|md] <url>`
+func runExtract(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "table", "output format: table, csv, json or md")
+	clipStart := fs.String("start", "", "extract from this offset, e.g. 40m or 40:00 (default the URL's t= parameter)")
+	clipEnd := fs.String("end", "", "extract up to this offset, e.g. 1h10m or 1:10:00")
+	cfgFlags := config.RegisterFlags(fs, false)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ytsum extract [-format table|csv|json|md] [flags] <url>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	var f export.Format
+	if *format != "table" {
+		var err error
+		if f, err = export.ParseStatementFormat(*format); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 2
+		}
+	}
+	start, end, err := parseClip(*clipStart, *clipEnd)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	extraction, err := core.Extract(context.Background(), core.Request{URL: fs.Arg(0), Start: start, End: end})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error extracting quotes and claims: %v\n", err)
+		return 1
+	}
+	if f != "" {
+		if err := export.WriteStatements(stdout, f, extraction); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(extraction.Statements) == 0 {
+		fmt.Fprintln(stdout, "No quotes or claims were found.")
+		return 0
+	}
+	var table bytes.Buffer
+	export.WriteStatements(&table, export.FormatMarkdown, extraction)
+	out, err := glamour.Render(table.String(), "auto")
+	if err != nil {
+		out = table.String()
+	}
+	fmt.Fprint(stdout, out)
+	fmt.Fprintf(stdout, "%d quotes · %d claims · %s · %s\n", len(extraction.Quotes()), len(extraction.Claims()), extraction.Model, extraction.Usage)
+	if extraction.Fallback != "" {
+		fmt.Fprintf(stdout, "Read from the transcript: the video could not be processed (%s)\n", extraction.Fallback)
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunExtract_Usage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "Usage: ytsum extract"},
+		{[]string{"-format", "pdf", "https://youtu.be/dQw4w9WgXcQ"}, `unknown statement format "pdf"`},
+		{[]string{"-start", "2:00", "-end", "1:00", "https://youtu.be/dQw4w9WgXcQ"}, "must come after -start"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runExtract(tt.args, &stdout, &stderr); code != 2 {
+			t.Errorf("runExtract(%q) = %d, want 2", tt.args, code)
+		}
+		if !strings.Contains(stderr.String(), tt.want) {
+			t.Errorf("runExtract(%q) stderr = %q, want %q", tt.args, stderr.String(), tt.want)
+		}
+	}
+}
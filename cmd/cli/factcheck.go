@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+	"github.com/charmbracelet/glamour"
+)
+
+// runFactCheck implements `ytsum factcheck [-format text|md|json] <url>`
+func runFactCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("factcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text, md or json")
+	clipStart := fs.String("start", "", "check claims from this offset, e.g. 40m or 40:00 (default the URL's t= parameter)")
+	clipEnd := fs.String("end", "", "check claims up to this offset, e.g. 1h10m or 1:10:00")
+	cfgFlags := config.RegisterFlags(fs, false)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ytsum factcheck [-format text|md|json] [flags] <url>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	var f export.Format
+	if *format != "text" {
+		var err error
+		if f, err = export.ParseFormat(*format); err != nil || (f != export.FormatMarkdown && f != export.FormatJSON) {
+			fmt.Fprintf(stderr, "Error: unknown fact check format %q (want text, md or json)\n", *format)
+			return 2
+		}
+	}
+	start, end, err := parseClip(*clipStart, *clipEnd)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	result, err := core.FactCheck(context.Background(), core.Request{URL: fs.Arg(0), Start: start, End: end}, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error fact-checking: %v\n", err)
+		return 1
+	}
+	if f != "" {
+		if err := export.WriteFactCheck(stdout, f, result); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	md := export.FactCheckMarkdown(result)
+	out, err := glamour.Render(md, "auto")
+	if err != nil {
+		out = md
+	}
+	fmt.Fprint(stdout, out)
+	fmt.Fprintf(stdout, "%s · %s\n", result.Model, result.Usage)
+	if result.Fallback != "" {
+		fmt.Fprintf(stdout, "Read from the transcript: the video could not be processed (%s)\n", result.Fallback)
+	}
+	return 0
+}
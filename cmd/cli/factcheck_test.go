@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunFactCheck_Usage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "Usage: ytsum factcheck"},
+		{[]string{"-format", "csv", "https://youtu.be/dQw4w9WgXcQ"}, `unknown fact check format "csv"`},
+		{[]string{"-start", "2:00", "-end", "1:00", "https://youtu.be/dQw4w9WgXcQ"}, "must come after -start"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runFactCheck(tt.args, &stdout, &stderr); code != 2 {
+			t.Errorf("runFactCheck(%q) = %d, want 2", tt.args, code)
+		}
+		if !strings.Contains(stderr.String(), tt.want) {
+			t.Errorf("runFactCheck(%q) stderr = %q, want %q", tt.args, stderr.String(), tt.want)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,9 +23,11 @@ type state int
 
 const (
 	menu state = iota
+	pickModel
 	inputURL
 	processing
 	displayResult
+	displayCompare
 )
 
 type model struct {
@@ -29,11 +38,35 @@ type model struct {
 	viewport     viewport.Model
 	result       string
 	renderedMD   string
+	notices      []string
+	comparing    bool
+	models       []core.ModelInfo
+	modelCursor  int
+	selected     map[string]bool
+	pickerErr    string
+	stats        string
+	toc          []tocEntry
+	tocOpen      bool
+	tocCursor    int
+	progress     chunkProgress
+	progressCh   <-chan core.Progress
+	panes        []comparePane
+	focus        int
+	opts         options
 	width        int
 	height       int
 }
 
-func initModel() model {
+// options holds the command line flags for the interactive mode
+type options struct {
+	exports       []export.Format
+	exportDir     string
+	compareModels []string
+	chunked       bool
+	start, end    time.Duration
+}
+
+func initModel(opts options) model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter YouTube video URL"
 	ti.CharLimit = 256
@@ -45,23 +78,28 @@ func initModel() model {
 		BorderForeground(lipgloss.Color("62")).
 		PaddingRight(2)
 
-	return model{
+	m := model{
 		state:    menu,
-		choices:  []string{"Generate content from YouTube video", "Exit"},
+		choices:  []string{"Generate content from YouTube video", "Compare models on a YouTube video", "Exit"},
 		urlInput: ti,
 		viewport: vp,
+		opts:     opts,
 		width:    80,
 		height:   24,
 	}
+	m.setModels(core.StaticModels)
+	return m
 }
 
 type resultMsg struct {
 	content string
+	usage   core.Usage
 	err     error
+	notices []string
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return loadModels
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -75,14 +113,37 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.Width = msg.Width - 4
 			m.viewport.Height = msg.Height - 6
 		}
+		if m.state == displayCompare {
+			layoutPanes(m.panes, m.width, m.height)
+		}
+		return m, nil
+
+	case modelsMsg:
+		m.setModels(msg.models)
+		return m, nil
+
+	case progressMsg:
+		m.progress.update(msg.Progress)
+		return m, waitForProgress(m.progressCh)
+
+	case compareMsg:
+		m.panes = newComparePanes(msg.comparisons, m.width, m.height)
+		m.focus = 0
+		m.state = displayCompare
 		return m, nil
 	
 	case resultMsg:
+		m.stats = ""
+		var headings []render.Heading
 		if msg.err != nil {
 			m.result = fmt.Sprintf("Error: %v", msg.err)
 		} else {
 			m.result = msg.content
+			doc := render.Analyze(msg.content)
+			m.stats = fmt.Sprintf("%d words · ~%d min read · %s", doc.Words, doc.ReadingMinutes, msg.usage)
+			headings = doc.Headings
 		}
+		m.notices = msg.notices
 		
 		renderer, _ := glamour.NewTermRenderer(
 			glamour.WithAutoStyle(),
@@ -97,6 +158,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		
 		m.viewport.SetContent(m.renderedMD)
+		m.toc = locateHeadings(headings, m.renderedMD)
+		m.tocOpen = false
+		m.tocCursor = 0
 		m.state = displayResult
 		return m, nil
 	}
@@ -117,16 +181,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.cursor++
 				}
 			case "enter":
-				if m.cursor == 0 {
-					m.state = inputURL
-					m.urlInput.Focus()
-					return m, textinput.Blink
+				if m.cursor < 2 {
+					m.comparing = m.cursor == 1
+					m.pickerErr = ""
+					m.state = pickModel
+					return m, nil
 				} else {
 					return m, tea.Quit
 				}
 			}
 		}
 
+	case pickModel:
+		return m.updateModelPicker(msg)
+
 	case inputURL:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
@@ -134,14 +202,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "ctrl+c", "q":
 				return m, tea.Quit
 			case "esc":
-				m.state = menu
+				m.state = pickModel
 				m.urlInput.Blur()
 				m.urlInput.SetValue("")
 				return m, nil
 			case "enter":
 				if m.urlInput.Value() != "" {
 					m.state = processing
-					return m, fetchSummary(m.urlInput.Value())
+					if m.comparing {
+						return m, fetchComparison(m.urlInput.Value(), m.selectedModels(), m.opts)
+					}
+					return m, m.startSummary(m.urlInput.Value(), m.models[m.modelCursor].Name)
 				}
 			}
 		}
@@ -159,9 +230,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case displayResult:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
+			if m.tocOpen {
+				return m.updateTOC(msg)
+			}
 			switch msg.String() {
 			case "ctrl+c", "q":
 				return m, tea.Quit
+			case "t":
+				m.tocOpen = len(m.toc) > 0
+				return m, nil
+			case "n", "]":
+				m.jumpHeading(1)
+				return m, nil
+			case "p", "[":
+				m.jumpHeading(-1)
+				return m, nil
 			case "esc":
 				m.state = menu
 				m.urlInput.Blur()
@@ -175,6 +258,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		m.viewport, cmd = m.viewport.Update(msg)
+
+	case displayCompare:
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc", "r":
+				m.state = menu
+				m.urlInput.Blur()
+				m.urlInput.SetValue("")
+				return m, nil
+			case "tab", "right", "l":
+				m.focus = (m.focus + 1) % len(m.panes)
+				return m, nil
+			case "shift+tab", "left", "h":
+				m.focus = (m.focus + len(m.panes) - 1) % len(m.panes)
+				return m, nil
+			}
+		}
+		m.panes[m.focus].viewport, cmd = m.panes[m.focus].viewport.Update(msg)
 	}
 
 	return m, cmd
@@ -202,6 +306,9 @@ func (m model) View() string {
 		s += "\nUse ↑/↓ arrows to navigate, Enter to select, q to quit.\n"
 		return s
 
+	case pickModel:
+		return m.modelPickerView(headerStyle)
+
 	case inputURL:
 		title := headerStyle.Render("Enter URL")
 		return fmt.Sprintf(
@@ -214,38 +321,168 @@ func (m model) View() string {
 	case processing:
 		title := headerStyle.Render("Processing")
 		spinner := "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏"
-		return fmt.Sprintf("%s\n\nProcessing your request... %s", title, string(spinner[0]))
+		return fmt.Sprintf("%s\n\nProcessing your request... %s\n\n%s", title, string(spinner[0]), m.progress.view())
 
 	case displayResult:
 		title := headerStyle.Render("Summary Results")
+		if m.stats != "" {
+			title += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#626262")).
+				Render("  " + m.stats)
+		}
 		
 		helpStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#626262")).
 			Render("• Use ↑/↓ arrows to scroll • Press 'r' or 'Esc' to return to menu • Press 'q' to quit")
+
+		if m.tocOpen {
+			help := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#626262")).
+				Render("• Use ↑/↓ to select a heading • Enter to jump • Press 't' or 'Esc' to close")
+			return fmt.Sprintf("%s\n\n%s\n\n%s", title, m.tocView(), help)
+		}
+		if len(m.toc) > 0 {
+			helpStyle += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#626262")).
+				Render("\n• Press 't' for contents • 'n'/'p' to jump to the next/previous heading")
+		}
+
+		for _, notice := range m.notices {
+			helpStyle += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#7D56F4")).
+				Render("\n• " + notice)
+		}
 		
 		return fmt.Sprintf("%s\n\n%s\n\n%s", 
 			title, 
 			m.viewport.View(), 
 			helpStyle)
+
+	case displayCompare:
+		title := headerStyle.Render("Model Comparison")
+
+		helpStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#626262")).
+			Render("• Tab/←/→ to switch pane • ↑/↓ to scroll • Press 'r' or 'Esc' to return to menu • Press 'q' to quit")
+
+		return fmt.Sprintf("%s\n\n%s\n\n%s",
+			title,
+			m.compareView(),
+			helpStyle)
 	}
 
 	return ""
 }
 
-func fetchSummary(url string) tea.Cmd {
+// fetchSummary summarizes url; a non-nil progress channel makes it a chunked
+// summary that reports each segment on progress and closes it when done
+func fetchSummary(url, modelName string, opts options, progress chan<- core.Progress) tea.Cmd {
 	return func() tea.Msg {
-		resp, err := core.SummarizeURL(url)
+		req := core.Request{URL: url, Model: modelName, Start: opts.start, End: opts.end}
+		if progress != nil {
+			defer close(progress)
+			req.Chunked = true
+			req.Progress = func(p core.Progress) { progress <- p }
+		}
+		result, err := core.Summarize(context.Background(), req)
+		if err != nil {
+			return resultMsg{err: err}
+		}
+		resp := result.Text
+
+		var notices []string
+		if result.Fallback != "" {
+			notices = append(notices, "Summarized from the transcript: the video could not be processed ("+result.Fallback+")")
+		} else if result.Input == config.InputTranscript {
+			notices = append(notices, "Summarized from the transcript")
+		}
+		if result.Segments > 1 {
+			notices = append(notices, fmt.Sprintf("Summarized in %d segments", result.Segments))
+		}
+		summary := &store.Summary{URL: url, Model: result.Model, Content: resp}
+		if err := saveSummary(summary); err != nil {
+			notices = append(notices, fmt.Sprintf("Summary was not saved: %v", err))
+		} else {
+			notices = append(notices, fmt.Sprintf("Saved as #%d", summary.ID))
+		}
+		for _, f := range opts.exports {
+			path, err := exportSummary(summary, f, opts.exportDir)
+			if err != nil {
+				notices = append(notices, fmt.Sprintf("Export to %s failed: %v", f, err))
+			} else {
+				notices = append(notices, "Exported "+path)
+			}
+		}
+
 		return resultMsg{
 			content: resp,
-			err:     err,
+			usage:   result.Usage,
+			notices: notices,
 		}
 	}
 }
 
 func main() {
-	p := tea.NewProgram(initModel(), tea.WithAltScreen())
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "search":
+			os.Exit(runSearch(os.Args[2:], os.Stdout, os.Stderr))
+		case "show":
+			os.Exit(runShow(os.Args[2:], os.Stdout, os.Stderr))
+		case "export":
+			os.Exit(runExport(os.Args[2:], os.Stdout, os.Stderr))
+		case "config":
+			os.Exit(runConfig(os.Args[2:], os.Stdout, os.Stderr))
+		case "extract":
+			os.Exit(runExtract(os.Args[2:], os.Stdout, os.Stderr))
+		case "factcheck":
+			os.Exit(runFactCheck(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	cfgFlags := config.RegisterFlags(flag.CommandLine, false)
+	exportFormats := flag.String("export", "", "comma separated formats to write after each summary ("+formatList()+")")
+	exportDir := flag.String("out", ".", "directory for exported files")
+	compareModels := flag.String("compare", "", "comma separated models preselected in compare mode")
+	chunked := flag.Bool("chunked", false, "summarize long videos in segments and merge the segment summaries")
+	clipStart := flag.String("start", "", "summarize from this offset, e.g. 40m or 40:00 (default the URL's t= parameter)")
+	clipEnd := flag.String("end", "", "summarize up to this offset, e.g. 1h10m or 1:10:00")
+	metricsFile := flag.String("metrics-file", "", "write Prometheus metrics to this file on exit")
+	metricsPush := flag.String("metrics-push", "", "push Prometheus metrics to this Pushgateway URL on exit")
+	flag.Parse()
+
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	formats, err := parseFormats(*exportFormats)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	start, end, err := parseClip(*clipStart, *clipEnd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	opts := options{
+		start:         start,
+		end:           end,
+		exports:       formats,
+		exportDir:     *exportDir,
+		compareModels: splitList(*compareModels),
+		chunked:       *chunked,
+	}
+	p := tea.NewProgram(initModel(opts), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	if err := emitMetrics(*metricsFile, *metricsPush); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+)
+
+// pushTimeout bounds how long the CLI waits on the Pushgateway at exit
+const pushTimeout = 10 * time.Second
+
+// emitMetrics writes the run's metrics to file (atomically, for the node
+// exporter's textfile collector) and/or pushes them to a Pushgateway
+func emitMetrics(file, pushURL string) error {
+	if file != "" {
+		tmp, err := os.CreateTemp(filepath.Dir(file), ".metrics-*")
+		if err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		if err := metrics.WriteText(tmp); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), file); err != nil {
+			return fmt.Errorf("failed to write metrics: %w", err)
+		}
+	}
+
+	if pushURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+		if err := metrics.Push(ctx, pushURL, "ytsum"); err != nil {
+			return fmt.Errorf("failed to push metrics: %w", err)
+		}
+	}
+	return nil
+}
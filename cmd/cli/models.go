@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type modelsMsg struct {
+	models []core.ModelInfo
+}
+
+// loadModels fetches the model catalog in the background; the picker shows
+// the static catalog until it arrives
+func loadModels() tea.Msg {
+	models := core.ListVideoModels(context.Background())
+	if len(models) == 0 {
+		models = core.StaticModels
+	}
+	return modelsMsg{models: models}
+}
+
+// setModels replaces the picker entries. The cursor and compare selection
+// follow the models they were on; when those are not in the new list the
+// cursor moves to the default model and the selection to the -compare flag.
+func (m *model) setModels(models []core.ModelInfo) {
+	current := core.DefaultModel()
+	if m.modelCursor < len(m.models) {
+		current = m.models[m.modelCursor].Name
+	}
+	m.models = models
+	m.modelCursor = 0
+	if i := indexOfModel(models, current); i >= 0 {
+		m.modelCursor = i
+	} else if i := indexOfModel(models, core.DefaultModel()); i >= 0 {
+		m.modelCursor = i
+	}
+
+	previous := m.selected
+	m.selected = map[string]bool{}
+	for name, on := range previous {
+		if on && indexOfModel(models, name) >= 0 {
+			m.selected[name] = true
+		}
+	}
+	if len(m.selected) == 0 {
+		for _, name := range m.opts.compareModels {
+			if indexOfModel(models, name) >= 0 {
+				m.selected[name] = true
+			}
+		}
+	}
+	if len(m.selected) == 0 {
+		for _, info := range models[:min(2, len(models))] {
+			m.selected[info.Name] = true
+		}
+	}
+}
+
+// indexOfModel returns the position of the named model in models, or -1
+func indexOfModel(models []core.ModelInfo, name string) int {
+	return slices.IndexFunc(models, func(info core.ModelInfo) bool { return info.Name == name })
+}
+
+// selectedModels returns the compare selection in catalog order
+func (m model) selectedModels() []string {
+	var names []string
+	for _, info := range m.models {
+		if m.selected[info.Name] {
+			names = append(names, info.Name)
+		}
+	}
+	return names
+}
+
+func (m model) updateModelPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.state = menu
+	case "up", "k":
+		if m.modelCursor > 0 {
+			m.modelCursor--
+		}
+	case "down", "j":
+		if m.modelCursor < len(m.models)-1 {
+			m.modelCursor++
+		}
+	case " ":
+		if m.comparing && len(m.models) > 0 {
+			name := m.models[m.modelCursor].Name
+			m.selected[name] = !m.selected[name]
+		}
+	case "enter":
+		if len(m.models) == 0 {
+			return m, nil
+		}
+		if m.comparing && len(m.selectedModels()) < 2 {
+			m.pickerErr = "Select at least two models to compare."
+			return m, nil
+		}
+		m.pickerErr = ""
+		m.state = inputURL
+		m.urlInput.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+func (m model) modelPickerView(headerStyle lipgloss.Style) string {
+	title := headerStyle.Render("Select Model")
+	help := "Use ↑/↓ to navigate, Enter to continue, Esc to go back, q to quit."
+	if m.comparing {
+		title = headerStyle.Render("Select Models to Compare")
+		help = "Use ↑/↓ to navigate, Space to toggle, Enter to continue, Esc to go back, q to quit."
+	}
+
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+	s := fmt.Sprintf("%s\n\n", title)
+	for i, info := range m.models {
+		cursor := " "
+		if m.modelCursor == i {
+			cursor = "▶"
+		}
+		check := ""
+		if m.comparing {
+			check = "[ ] "
+			if m.selected[info.Name] {
+				check = "[x] "
+			}
+		}
+		details := dim.Render(fmt.Sprintf("%s · %dk in / %dk out", info.Name, info.InputTokenLimit/1000, info.OutputTokenLimit/1000))
+		s += fmt.Sprintf("%s %s%s  %s\n", cursor, check, info.DisplayName, details)
+	}
+	if m.pickerErr != "" {
+		s += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#E06C75")).Render(m.pickerErr) + "\n"
+	}
+	s += "\n" + help + "\n"
+	return s
+}
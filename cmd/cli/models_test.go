@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+)
+
+func TestSetModels_KeepsPicks(t *testing.T) {
+	m := initModel(options{})
+	m.modelCursor = indexOfModel(m.models, "gemini-2.0-flash")
+	m.selected = map[string]bool{"gemini-2.0-flash": true, "gemini-2.5-pro-preview-05-06": true}
+
+	// The fetched catalog arrives after the user picked, in a different order
+	m.setModels([]core.ModelInfo{
+		{Name: "gemini-new"},
+		{Name: "gemini-2.5-pro-preview-05-06"},
+		{Name: "gemini-2.0-flash"},
+	})
+	if got := m.models[m.modelCursor].Name; got != "gemini-2.0-flash" {
+		t.Errorf("cursor on %q, want the picked gemini-2.0-flash", got)
+	}
+	if got := m.selectedModels(); !slices.Equal(got, []string{"gemini-2.5-pro-preview-05-06", "gemini-2.0-flash"}) {
+		t.Errorf("selectedModels() = %v, want the picked models", got)
+	}
+
+	// Nothing picked is left: fall back to the defaults
+	m.setModels([]core.ModelInfo{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	if m.modelCursor != 0 {
+		t.Errorf("cursor = %d, want 0 when neither the pick nor the default model is listed", m.modelCursor)
+	}
+	if got := m.selectedModels(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("selectedModels() = %v, want the first two models", got)
+	}
+}
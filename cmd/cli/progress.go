@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressMsg carries an event of a chunked summary
+type progressMsg struct {
+	core.Progress
+}
+
+// waitForProgress delivers the next event of a chunked summary. It returns
+// nil once the summary is finished and the channel closed.
+func waitForProgress(ch <-chan core.Progress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return progressMsg{p}
+	}
+}
+
+// startSummary summarizes url, listening for segment progress when -chunked is set
+func (m *model) startSummary(url, modelName string) tea.Cmd {
+	m.progress = chunkProgress{}
+	if !m.opts.chunked {
+		return fetchSummary(url, modelName, m.opts, nil)
+	}
+	ch := make(chan core.Progress, 16)
+	m.progressCh = ch
+	return tea.Batch(fetchSummary(url, modelName, m.opts, ch), waitForProgress(ch))
+}
+
+// chunkProgress follows the segments of a chunked summary
+type chunkProgress struct {
+	segments []core.Progress
+	merge    *core.Progress
+}
+
+func (c *chunkProgress) update(p core.Progress) {
+	if p.Stage == core.StageMerge {
+		c.merge = &p
+		return
+	}
+	if len(c.segments) != p.Segments {
+		c.segments = make([]core.Progress, p.Segments)
+	}
+	c.segments[p.Segment-1] = p
+}
+
+// view lists each segment with its state: waiting, running, done or failed
+func (c chunkProgress) view() string {
+	if len(c.segments) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, s := range c.segments {
+		state := "waiting"
+		switch {
+		case s.Err != nil:
+			state = "failed: " + s.Err.Error()
+		case s.Done:
+			state = "done"
+		case s.Stage != "":
+			state = "summarizing..."
+		}
+		fmt.Fprintf(&b, "  Segment %d/%d", i+1, len(c.segments))
+		if s.Stage != "" {
+			fmt.Fprintf(&b, " [%s-%s]", transcript.Timestamp(s.Start), transcript.Timestamp(s.End))
+		}
+		fmt.Fprintf(&b, " %s\n", state)
+	}
+	if c.merge != nil {
+		state := "merging..."
+		switch {
+		case c.merge.Err != nil:
+			state = "merge failed: " + c.merge.Err.Error()
+		case c.merge.Done:
+			state = "merged"
+		}
+		fmt.Fprintf(&b, "  %s\n", state)
+	}
+	return b.String()
+}
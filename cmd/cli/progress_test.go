@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+)
+
+func TestChunkProgress(t *testing.T) {
+	var c chunkProgress
+	if got := c.view(); got != "" {
+		t.Errorf("view() before any event = %q, want empty", got)
+	}
+
+	c.update(core.Progress{Stage: core.StageSegment, Segment: 2, Segments: 3, Start: 20 * time.Minute, End: 40 * time.Minute})
+	c.update(core.Progress{Stage: core.StageSegment, Segment: 1, Segments: 3, End: 20 * time.Minute, Done: true})
+	c.update(core.Progress{Stage: core.StageSegment, Segment: 3, Segments: 3, Start: 40 * time.Minute, End: time.Hour, Done: true, Err: errors.New("quota")})
+	c.update(core.Progress{Stage: core.StageMerge, Segments: 3})
+
+	got := c.view()
+	for _, want := range []string{
+		"Segment 1/3 [0:00-20:00] done",
+		"Segment 2/3 [20:00-40:00] summarizing...",
+		"Segment 3/3 [40:00-1:00:00] failed: quota",
+		"merging...",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("view() = %q, missing %q", got, want)
+		}
+	}
+}
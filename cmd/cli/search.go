@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+	"github.com/charmbracelet/glamour"
+)
+
+// runSearch implements `ytsum search [-semantic] [-n N] <query>`
+func runSearch(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	semantic := fs.Bool("semantic", false, "rank by embedding similarity instead of full-text match (requires EMBEDDING_MODEL)")
+	limit := fs.Int("n", 10, "maximum number of results")
+	cfgFlags := config.RegisterFlags(fs, false)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ytsum search [-semantic] [-n N] <query>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		fs.Usage()
+		return 2
+	}
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	s, err := core.OpenStore()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening summary store: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	results, err := s.Search(context.Background(), store.Query{Text: query, Semantic: *semantic, Limit: *limit})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error searching summaries: %v\n", err)
+		return 1
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(stdout, "No matching summaries.")
+		return 0
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(stdout, "#%-4d %s\n", r.ID, r.Title)
+		fmt.Fprintf(stdout, "      %s · %s · %s\n", r.URL, r.Model, r.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if r.Snippet != "" {
+			fmt.Fprintf(stdout, "      %s\n", strings.Join(strings.Fields(r.Snippet), " "))
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintln(stdout, "Use `ytsum show <id>` to read a summary.")
+	return 0
+}
+
+// runShow implements `ytsum show <id>`
+func runShow(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cfgFlags := config.RegisterFlags(fs, false)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ytsum show [flags] <id>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid summary id %q\n", fs.Arg(0))
+		return 2
+	}
+	if err := configure(cfgFlags); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	s, err := core.OpenStore()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error opening summary store: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	summary, err := s.Get(context.Background(), id, "")
+	if errors.Is(err, store.ErrNotFound) {
+		fmt.Fprintf(stderr, "No summary with id %d\n", id)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading summary: %v\n", err)
+		return 1
+	}
+
+	out, err := glamour.Render(summary.Content, "auto")
+	if err != nil {
+		out = summary.Content
+	}
+	fmt.Fprint(stdout, out)
+	return 0
+}
+
+// saveSummary persists a generated summary so it can be found with `ytsum search`
+func saveSummary(summary *store.Summary) error {
+	s, err := core.OpenStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	return s.Save(context.Background(), summary)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// tocEntry is a heading of the displayed summary and the viewport line it starts on
+type tocEntry struct {
+	heading render.Heading
+	line    int
+}
+
+// locateHeadings finds each heading in the glamour output, in document order.
+// Headings are matched on their first few words because long ones wrap.
+func locateHeadings(headings []render.Heading, rendered string) []tocEntry {
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	var entries []tocEntry
+	next := 0
+	for _, h := range headings {
+		needle := h.Text
+		if words := strings.Fields(needle); len(words) > 3 {
+			needle = strings.Join(words[:3], " ")
+		}
+		for i := next; i < len(lines); i++ {
+			if strings.Contains(lines[i], needle) {
+				entries = append(entries, tocEntry{heading: h, line: i})
+				next = i + 1
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// jumpHeading scrolls the result viewport to the next (dir > 0) or previous heading
+func (m *model) jumpHeading(dir int) {
+	offset := m.viewport.YOffset
+	if dir > 0 {
+		for _, e := range m.toc {
+			if e.line > offset {
+				m.viewport.SetYOffset(e.line)
+				return
+			}
+		}
+		return
+	}
+	for i := len(m.toc) - 1; i >= 0; i-- {
+		if m.toc[i].line < offset {
+			m.viewport.SetYOffset(m.toc[i].line)
+			return
+		}
+	}
+	m.viewport.GotoTop()
+}
+
+// updateTOC handles keys while the contents list is open
+func (m model) updateTOC(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "t":
+		m.tocOpen = false
+	case "up", "k":
+		if m.tocCursor > 0 {
+			m.tocCursor--
+		}
+	case "down", "j":
+		if m.tocCursor < len(m.toc)-1 {
+			m.tocCursor++
+		}
+	case "enter":
+		m.viewport.SetYOffset(m.toc[m.tocCursor].line)
+		m.tocOpen = false
+	}
+	return m, nil
+}
+
+// tocView lists the summary's headings, indented by level
+func (m model) tocView() string {
+	top := m.toc[0].heading.Level
+	for _, e := range m.toc {
+		top = min(top, e.heading.Level)
+	}
+
+	var b strings.Builder
+	for i, e := range m.toc {
+		cursor := " "
+		if m.tocCursor == i {
+			cursor = "▶"
+		}
+		fmt.Fprintf(&b, "%s %s%s\n", cursor, strings.Repeat("  ", e.heading.Level-top), e.heading.Text)
+	}
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Render(strings.TrimSuffix(b.String(), "\n"))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestLocateHeadings(t *testing.T) {
+	md := "# Video Summary\n\nIntro paragraph.\n\n## Key Points From The Opening Segment\n\n- one\n- two\n\n## Conclusion\n\nThe end.\n"
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := r.Render(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toc := locateHeadings(render.Analyze(md).Headings, rendered)
+	if len(toc) != 3 {
+		t.Fatalf("located %d of 3 headings in:\n%s", len(toc), ansi.Strip(rendered))
+	}
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	for i, e := range toc {
+		if i > 0 && e.line <= toc[i-1].line {
+			t.Errorf("headings out of order: %+v", toc)
+		}
+		if !strings.Contains(lines[e.line], strings.Fields(e.heading.Text)[0]) {
+			t.Errorf("line %d = %q, want heading %q", e.line, lines[e.line], e.heading.Text)
+		}
+	}
+}
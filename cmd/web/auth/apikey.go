@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader carries an API key; "Authorization: Bearer <key>" works too
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys authenticates programmatic clients by a static key per user
+type APIKeys struct {
+	// users is keyed by the SHA-256 of each key so lookups don't compare secrets directly
+	users map[[sha256.Size]byte]string
+}
+
+// NewAPIKeys returns an authenticator for keys, which maps a user ID to its key
+func NewAPIKeys(keys map[string]string) *APIKeys {
+	a := &APIKeys{users: make(map[[sha256.Size]byte]string, len(keys))}
+	for user, key := range keys {
+		a.users[sha256.Sum256([]byte(key))] = user
+	}
+	return a
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeys) Authenticate(r *http.Request) (*User, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = strings.TrimSpace(token)
+		}
+	}
+	if key == "" {
+		return nil, nil
+	}
+
+	user, ok := a.users[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return &User{ID: user, Name: user, Method: "api_key"}, nil
+}
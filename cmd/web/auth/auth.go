@@ -0,0 +1,142 @@
+// Package auth authenticates requests to the web server.
+//
+// Authentication is pluggable: the Middleware tries each configured
+// Authenticator in turn. API keys serve programmatic clients, while browser
+// users log in through an OpenID Connect provider and are then recognised by
+// a signed session cookie. Every authenticated request carries a User in its
+// context so work can be attributed to whoever asked for it.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidCredentials is returned when a request presents credentials that
+// do not authenticate anyone
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Anonymous is the user ID recorded when the server runs without auth
+const Anonymous = "anonymous"
+
+// User is an authenticated caller
+type User struct {
+	// ID identifies the user across requests, e.g. an API key owner or an email
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Method is how the user authenticated: "api_key" or "oidc"
+	Method string `json:"method"`
+}
+
+// DisplayName returns the friendliest available name
+func (u *User) DisplayName() string {
+	switch {
+	case u.Name != "":
+		return u.Name
+	case u.Email != "":
+		return u.Email
+	}
+	return u.ID
+}
+
+// Authenticator recognises the caller of a request. It returns nil and no
+// error when the request carries none of its credentials, and
+// ErrInvalidCredentials when they are present but wrong.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*User, error)
+}
+
+type contextKey struct{}
+
+// WithUser returns a copy of ctx carrying u
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// FromContext returns the authenticated user, or nil
+func FromContext(ctx context.Context) *User {
+	u, _ := ctx.Value(contextKey{}).(*User)
+	return u
+}
+
+// UserID returns the ID of the authenticated user, or Anonymous
+func UserID(ctx context.Context) string {
+	if u := FromContext(ctx); u != nil {
+		return u.ID
+	}
+	return Anonymous
+}
+
+// Middleware rejects requests that no Authenticator accepts
+type Middleware struct {
+	Authenticators []Authenticator
+	// LoginURL is where browsers are sent to log in; empty answers 401 instead
+	LoginURL string
+	// Public lists paths (or prefixes ending in "/") that need no credentials
+	Public []string
+}
+
+// Handler wraps next with authentication
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, a := range m.Authenticators {
+			u, err := a.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="summarizer"`)
+				http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+				return
+			}
+			if u != nil {
+				next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), u)))
+				return
+			}
+		}
+
+		if m.isPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		m.challenge(w, r)
+	})
+}
+
+func (m *Middleware) isPublic(path string) bool {
+	for _, p := range m.Public {
+		if path == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// challenge asks an unauthenticated caller to log in. Browsers are redirected
+// to the login page, htmx requests get an HX-Redirect, and API clients a 401.
+func (m *Middleware) challenge(w http.ResponseWriter, r *http.Request) {
+	if m.LoginURL != "" {
+		login := m.LoginURL + "?next=" + url.QueryEscape(r.URL.RequestURI())
+		switch {
+		case r.Header.Get("HX-Request") == "true":
+			w.Header().Set("HX-Redirect", m.LoginURL)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html"):
+			http.Redirect(w, r, login, http.StatusFound)
+			return
+		}
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="summarizer"`)
+	http.Error(w, "Authentication required", http.StatusUnauthorized)
+}
+
+// safeRedirect returns next when it is a local path, or "/" otherwise, so the
+// login flow cannot be used as an open redirect
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
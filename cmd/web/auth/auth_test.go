@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth/mockidp"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+// whoami echoes the authenticated user ID
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(UserID(r.Context())))
+})
+
+func TestAPIKeys(t *testing.T) {
+	keys := NewAPIKeys(map[string]string{"alice": "key-a"})
+	mw := &Middleware{Authenticators: []Authenticator{keys}, Public: []string{"/health", "/static/"}}
+	h := mw.Handler(whoami)
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		value    string
+		wantCode int
+		wantBody string
+	}{
+		{"header", "/summarize", APIKeyHeader, "key-a", http.StatusOK, "alice"},
+		{"bearer", "/summarize", "Authorization", "Bearer key-a", http.StatusOK, "alice"},
+		{"wrong key", "/summarize", APIKeyHeader, "nope", http.StatusUnauthorized, ""},
+		{"missing", "/summarize", "", "", http.StatusUnauthorized, ""},
+		{"public", "/health", "", "", http.StatusOK, Anonymous},
+		{"public prefix", "/static/js/app.js", "", "", http.StatusOK, Anonymous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("user = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMiddleware_BrowserRedirect(t *testing.T) {
+	mw := &Middleware{LoginURL: "/login"}
+	h := mw.Handler(whoami)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=go", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/login?next=%2Fsearch%3Fq%3Dgo" {
+		t.Errorf("browser got %d to %q, want redirect to login", rec.Code, rec.Header().Get("Location"))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/summarize", nil)
+	req.Header.Set("HX-Request", "true")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("HX-Redirect") != "/login" {
+		t.Errorf("htmx got %d with HX-Redirect %q", rec.Code, rec.Header().Get("HX-Redirect"))
+	}
+}
+
+func TestSessions(t *testing.T) {
+	s := NewSessions([]byte("secret"), time.Hour)
+	rec := httptest.NewRecorder()
+	if err := s.Issue(rec, httptest.NewRequest(http.MethodGet, "/", nil), &User{ID: "bob", Method: "oidc"}); err != nil {
+		t.Fatal(err)
+	}
+	cookie := rec.Result().Cookies()[0]
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie %+v should be HttpOnly and SameSite=Lax", cookie)
+	}
+
+	authenticate := func(value string) *User {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookie, Value: value})
+		u, err := s.Authenticate(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	if u := authenticate(cookie.Value); u == nil || u.ID != "bob" {
+		t.Errorf("valid session = %+v, want bob", u)
+	}
+	payload, mac, _ := strings.Cut(cookie.Value, ".")
+	if u := authenticate(payload[:len(payload)-2] + "xx." + mac); u != nil {
+		t.Error("tampered session should not authenticate")
+	}
+	if u := authenticate(cookie.Value + "x"); u != nil {
+		t.Error("session with a bad MAC should not authenticate")
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if u := authenticate(cookie.Value); u != nil {
+		t.Error("expired session should not authenticate")
+	}
+}
+
+// startOIDCApp serves whoami behind an OIDC login against a mock provider
+// that logs everyone in as user, and returns it with a cookie-keeping client
+func startOIDCApp(t *testing.T, user mockidp.User) (*httptest.Server, *http.Client) {
+	t.Helper()
+	idp, err := mockidp.Start("127.0.0.1:0", "summarizer", user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { idp.Close() })
+
+	sessions := NewSessions([]byte("secret"), time.Hour)
+	mux := http.NewServeMux()
+	app := httptest.NewServer(mux)
+	t.Cleanup(app.Close)
+
+	provider, err := NewOIDC(context.Background(), config.OIDC{
+		Issuer:      idp.URL,
+		ClientID:    "summarizer",
+		RedirectURL: app.URL + "/auth/callback",
+	}, sessions)
+	if err != nil {
+		t.Fatalf("NewOIDC() error = %v", err)
+	}
+	mw := &Middleware{
+		Authenticators: []Authenticator{sessions},
+		LoginURL:       "/login",
+		Public:         []string{"/login", "/auth/callback"},
+	}
+	mux.HandleFunc("/login", provider.LoginHandler)
+	mux.HandleFunc("/auth/callback", provider.CallbackHandler)
+	mux.HandleFunc("/logout", provider.LogoutHandler)
+	mux.Handle("/me", whoami)
+	app.Config.Handler = mw.Handler(mux)
+
+	jar, _ := cookiejar.New(nil)
+	return app, &http.Client{Jar: jar}
+}
+
+func TestOIDC_LoginFlow(t *testing.T) {
+	app, client := startOIDCApp(t, mockidp.User{Subject: "42", Email: "carol@example.com", EmailVerified: true, Name: "Carol"})
+
+	req, _ := http.NewRequest(http.MethodGet, app.URL+"/me", nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := readBody(t, resp)
+	if resp.StatusCode != http.StatusOK || body != "carol@example.com" {
+		t.Fatalf("after login got %d %q, want carol's page", resp.StatusCode, body)
+	}
+
+	resp, err = client.Post(app.URL+"/logout", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	resp, err = client.Get(app.URL + "/me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("after logout got %d, want 401", resp.StatusCode)
+	}
+
+	// A callback without the flow cookie must not log anyone in
+	resp, err = http.Get(app.URL + "/auth/callback?code=x&state=y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("forged callback got %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestOIDC_UnverifiedEmail(t *testing.T) {
+	// Claiming an admin's address must not yield the admin's ID
+	app, client := startOIDCApp(t, mockidp.User{Subject: "42", Email: "admin@example.com", Name: "Mallory"})
+
+	req, _ := http.NewRequest(http.MethodGet, app.URL+"/me", nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(t, resp); resp.StatusCode != http.StatusOK || body != "42" {
+		t.Errorf("login with an unverified email got %d %q, want the subject 42", resp.StatusCode, body)
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	for in, want := range map[string]string{
+		"/search?q=x":        "/search?q=x",
+		"":                   "/",
+		"https://evil.com":   "/",
+		"//evil.com":         "/",
+		"/\\evil.com":        "/",
+		"javascript:alert()": "/",
+	} {
+		if got := safeRedirect(in); got != want {
+			t.Errorf("safeRedirect(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,217 @@
+// Package mockidp is a minimal OpenID Connect provider for local development
+// and tests. It signs every login in as one fixed user without prompting.
+// Never expose it outside localhost.
+package mockidp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// User is the identity every login receives
+type User struct {
+	Subject string
+	Email   string
+	// EmailVerified sets the email_verified claim
+	EmailVerified bool
+	Name          string
+}
+
+// DefaultUser is used when New is given an empty user
+var DefaultUser = User{Subject: "dev-user", Email: "dev@example.com", EmailVerified: true, Name: "Dev User"}
+
+// Server is a running mock provider
+type Server struct {
+	// URL is the issuer URL
+	URL      string
+	ClientID string
+	User     User
+
+	key      *rsa.PrivateKey
+	signer   jose.Signer
+	listener net.Listener
+	server   *http.Server
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is an issued authorization code awaiting exchange
+type grant struct {
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// Start runs a provider on addr (e.g. "127.0.0.1:0") that accepts clientID
+func Start(addr, clientID string, user User) (*Server, error) {
+	if user == (User{}) {
+		user = DefaultUser
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "mock"))
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		URL:      "http://" + listener.Addr().String(),
+		ClientID: clientID,
+		User:     user,
+		key:      key,
+		signer:   signer,
+		listener: listener,
+		codes:    map[string]grant{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+// Close stops the provider
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize approves every request immediately
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != s.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "unknown client or unsupported response_type", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	s.mu.Lock()
+	s.codes[code] = grant{nonce: q.Get("nonce"), challenge: q.Get("code_challenge"), redirectURI: redirect.String()}
+	s.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+	}
+
+	s.mu.Lock()
+	g, found := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !found, clientID != s.ClientID, r.PostForm.Get("redirect_uri") != g.redirectURI:
+		tokenError(w, "invalid_grant")
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := struct {
+		jwt.Claims
+		Nonce         string `json:"nonce,omitempty"`
+		Email         string `json:"email,omitempty"`
+		EmailVerified bool   `json:"email_verified,omitempty"`
+		Name          string `json:"name,omitempty"`
+	}{
+		Claims: jwt.Claims{
+			Issuer:   s.URL,
+			Subject:  s.User.Subject,
+			Audience: jwt.Audience{s.ClientID},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Nonce:         g.nonce,
+		Email:         s.User.Email,
+		EmailVerified: s.User.EmailVerified,
+		Name:          s.User.Name,
+	}
+	idToken, err := jwt.Signed(s.signer).Claims(claims).Serialize()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to sign token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &s.key.PublicKey, KeyID: "mock", Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// flowCookie remembers the state of a login between redirect and callback
+const flowCookie = "summarizer_oidc"
+
+// flowTTL bounds how long a user may take to log in at the provider
+const flowTTL = 10 * time.Minute
+
+// OIDC logs browser users in with an OpenID Connect provider using the
+// authorization code flow with PKCE, then starts a session for them
+type OIDC struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	sessions *Sessions
+}
+
+type loginFlow struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Next     string `json:"next"`
+	Expires  int64  `json:"exp"`
+}
+
+// NewOIDC discovers the provider at cfg.Issuer
+func NewOIDC(ctx context.Context, cfg config.OIDC, sessions *Sessions) (*OIDC, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	return &OIDC{
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: string(cfg.ClientSecret),
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       append([]string{oidc.ScopeOpenID}, scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		sessions: sessions,
+	}, nil
+}
+
+// LoginHandler redirects the browser to the provider. The ?next= parameter
+// names the local page to return to afterwards.
+func (o *OIDC) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flow := loginFlow{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: oauth2.GenerateVerifier(),
+		Next:     safeRedirect(r.URL.Query().Get("next")),
+		Expires:  time.Now().Add(flowTTL).Unix(),
+	}
+	payload, err := json.Marshal(flow)
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	o.sessions.setCookie(w, r, flowCookie, o.sessions.sign(payload), time.Unix(flow.Expires, 0))
+
+	http.Redirect(w, r, o.oauth.AuthCodeURL(flow.State,
+		oidc.Nonce(flow.Nonce),
+		oauth2.S256ChallengeOption(flow.Verifier),
+	), http.StatusFound)
+}
+
+// CallbackHandler completes the login and starts a session
+func (o *OIDC) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flow, ok := o.readFlow(r)
+	o.sessions.setCookie(w, r, flowCookie, "", time.Unix(0, 0))
+	if !ok || r.URL.Query().Get("state") != flow.State {
+		http.Error(w, "Login expired or was tampered with, please try again", http.StatusBadRequest)
+		return
+	}
+	if e := r.URL.Query().Get("error"); e != "" {
+		http.Error(w, "Login failed: "+e, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := o.exchange(r.Context(), r.URL.Query().Get("code"), flow)
+	if err != nil {
+		slog.WarnContext(r.Context(), "OIDC login failed", "error", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	if err := o.sessions.Issue(w, r, user); err != nil {
+		http.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "User logged in", "user", user.ID)
+	http.Redirect(w, r, flow.Next, http.StatusFound)
+}
+
+// LogoutHandler ends the browser session
+func (o *OIDC) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	o.sessions.Clear(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (o *OIDC) readFlow(r *http.Request) (loginFlow, bool) {
+	var flow loginFlow
+	cookie, err := r.Cookie(flowCookie)
+	if err != nil {
+		return flow, false
+	}
+	payload, ok := o.sessions.verify(cookie.Value)
+	if !ok || json.Unmarshal(payload, &flow) != nil {
+		return flow, false
+	}
+	return flow, time.Now().Unix() < flow.Expires
+}
+
+// exchange trades the authorization code for a verified ID token
+func (o *OIDC) exchange(ctx context.Context, code string, flow loginFlow) (*User, error) {
+	token, err := o.oauth.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange: %w", err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	idToken, err := o.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("id token: %w", err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	}
+
+	var claims struct {
+		Email string `json:"email"`
+		// EmailVerified is a boolean, or the string "true" from some providers
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("id token claims: %w", err)
+	}
+
+	// Admins and quotas key on the ID, so an email is only used once the
+	// provider vouches for it; anyone can claim an unverified address
+	user := &User{ID: idToken.Subject, Name: claims.Name, Email: claims.Email, Method: "oidc"}
+	if claims.Email != "" && (claims.EmailVerified == true || claims.EmailVerified == "true") {
+		user.ID = claims.Email
+	}
+	if user.Name == "" {
+		user.Name = claims.PreferredUsername
+	}
+	return user, nil
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionCookie is the name of the browser session cookie
+const SessionCookie = "summarizer_session"
+
+// Sessions keeps browser users logged in with an HMAC-signed cookie. The
+// cookie holds the user itself, so no server-side session state is needed.
+type Sessions struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+type session struct {
+	User    *User `json:"user"`
+	Expires int64 `json:"exp"`
+}
+
+// NewSessions returns a session manager signing cookies with secret
+func NewSessions(secret []byte, ttl time.Duration) *Sessions {
+	return &Sessions{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// Issue logs u in by setting the session cookie
+func (s *Sessions) Issue(w http.ResponseWriter, r *http.Request, u *User) error {
+	expires := s.now().Add(s.ttl)
+	payload, err := json.Marshal(session{User: u, Expires: expires.Unix()})
+	if err != nil {
+		return err
+	}
+	s.setCookie(w, r, SessionCookie, s.sign(payload), expires)
+	return nil
+}
+
+// Clear logs the browser out
+func (s *Sessions) Clear(w http.ResponseWriter, r *http.Request) {
+	s.setCookie(w, r, SessionCookie, "", time.Unix(0, 0))
+}
+
+// Authenticate implements Authenticator. A missing, tampered or expired
+// cookie is treated as no credentials so the browser is sent to log in again.
+func (s *Sessions) Authenticate(r *http.Request) (*User, error) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return nil, nil
+	}
+	payload, ok := s.verify(cookie.Value)
+	if !ok {
+		return nil, nil
+	}
+
+	var sess session
+	if err := json.Unmarshal(payload, &sess); err != nil || sess.User == nil {
+		return nil, nil
+	}
+	if s.now().Unix() >= sess.Expires {
+		return nil, nil
+	}
+	return sess.User, nil
+}
+
+func (s *Sessions) setCookie(w http.ResponseWriter, r *http.Request, name, value string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		// Lax keeps cross-site form posts from carrying the session
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sign returns payload and its MAC, both base64url encoded
+func (s *Sessions) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns the payload of a value produced by sign
+func (s *Sessions) verify(value string) ([]byte, bool) {
+	encPayload, encMAC, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, false
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return nil, false
+	}
+	got, err := enc.DecodeString(encMAC)
+	if err != nil {
+		return nil, false
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return payload, hmac.Equal(got, mac.Sum(nil))
+}
@@ -2,6 +2,22 @@ module github.com/BrunodsLilly/Summarizer/cmd/web
 
 go 1.24.3
 
-require github.com/a-h/templ v0.3.887
+require (
+	github.com/a-h/templ v0.3.887
+	github.com/coreos/go-oidc/v3 v3.14.1
+	github.com/go-jose/go-jose/v4 v4.0.5
+	github.com/prometheus/client_golang v1.22.0
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+	golang.org/x/oauth2 v0.30.0
+	golang.org/x/time v0.11.0
+)
 
-require github.com/yuin/goldmark v1.7.12 // indirect
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/yuin/goldmark v1.7.12 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+	golang.org/x/crypto v0.36.0 // indirect
+)
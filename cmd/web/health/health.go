@@ -0,0 +1,180 @@
+// Package health implements the readiness probe: named checks of the server's
+// dependencies, run concurrently and reported as JSON per component.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultTimeout bounds each check when Checker.Timeout is zero
+const defaultTimeout = 5 * time.Second
+
+// defaultDeepTTL is how long deep results are reused when Checker.DeepTTL is zero
+const defaultDeepTTL = 30 * time.Second
+
+// Statuses reported per component and overall
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+	StatusSkipped  = "skipped"
+)
+
+// Check probes one component; Run returns nil when it is healthy
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+	// Optional failures are reported but leave the server ready
+	Optional bool
+	// Deep checks call remote APIs and only run for /readyz?deep=1. Their
+	// results are reused for Checker.DeepTTL so the public probe can't be used
+	// to run up API calls.
+	Deep bool
+}
+
+// Component is the outcome of one check
+type Component struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the body served by the readiness probe
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+// Checker runs Checks and serves them as a readiness probe
+type Checker struct {
+	Checks []Check
+	// Timeout bounds each check
+	Timeout time.Duration
+	// DeepTTL is how long a deep check's result is served before it runs again
+	DeepTTL time.Duration
+
+	mu   sync.Mutex
+	deep map[string]cached
+}
+
+// cached is the last result of a deep check
+type cached struct {
+	Component
+	at time.Time
+}
+
+// Run runs every check concurrently. The report fails if a required check
+// fails and is degraded if only optional checks fail.
+func (c *Checker) Run(ctx context.Context, deep bool) Report {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	components := make([]Component, len(c.Checks))
+	var wg sync.WaitGroup
+	for i, check := range c.Checks {
+		if check.Deep && !deep {
+			components[i] = Component{Status: StatusSkipped}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if check.Deep {
+				components[i] = c.runDeep(ctx, check, timeout)
+			} else {
+				components[i] = run(ctx, check, timeout)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Component, len(c.Checks))}
+	for i, check := range c.Checks {
+		report.Components[check.Name] = components[i]
+		if components[i].Status != StatusFail {
+			continue
+		}
+		if !check.Optional {
+			report.Status = StatusFail
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run runs one check within timeout
+func run(ctx context.Context, check Check, timeout time.Duration) Component {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	component := Component{Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		component.Status = StatusFail
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// runDeep returns the cached result of a deep check, running it when the
+// cache is older than DeepTTL. Concurrent probes wait for the one run in
+// flight rather than starting their own.
+func (c *Checker) runDeep(ctx context.Context, check Check, timeout time.Duration) Component {
+	ttl := c.DeepTTL
+	if ttl == 0 {
+		ttl = defaultDeepTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.deep[check.Name]; ok && time.Since(last.at) < ttl {
+		return last.Component
+	}
+	// A caller hanging up must not cache a failure for everyone else
+	component := run(context.WithoutCancel(ctx), check, timeout)
+	if c.deep == nil {
+		c.deep = map[string]cached{}
+	}
+	c.deep[check.Name] = cached{Component: component, at: time.Now()}
+	return component
+}
+
+// ServeHTTP writes the report, with status 503 when a required check failed
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context(), r.URL.Query().Get("deep") != "")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Reachable returns a check that url answers HTTP requests without a server error
+func Reachable(client *http.Client, url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestChecker_ServeHTTP(t *testing.T) {
+	deepRan := false
+	tests := []struct {
+		name       string
+		checks     []Check
+		query      string
+		wantCode   int
+		wantStatus string
+	}{
+		{"all ok", []Check{{Name: "config", Run: ok}}, "", http.StatusOK, StatusOK},
+		{"required failure", []Check{
+			{Name: "config", Run: ok},
+			{Name: "credentials", Run: func(context.Context) error { return errors.New("no key") }},
+		}, "", http.StatusServiceUnavailable, StatusFail},
+		{"optional failure", []Check{
+			{Name: "store", Run: func(context.Context) error { return errors.New("locked") }, Optional: true},
+		}, "", http.StatusOK, StatusDegraded},
+		{"deep skipped", []Check{
+			{Name: "gemini", Run: func(context.Context) error { deepRan = true; return errors.New("down") }, Deep: true},
+		}, "", http.StatusOK, StatusOK},
+		{"deep requested", []Check{
+			{Name: "gemini", Run: func(context.Context) error { return errors.New("down") }, Deep: true},
+		}, "?deep=1", http.StatusServiceUnavailable, StatusFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			(&Checker{Checks: tt.checks}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz"+tt.query, nil))
+
+			var report Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantCode || report.Status != tt.wantStatus {
+				t.Errorf("got %d %q, want %d %q", rec.Code, report.Status, tt.wantCode, tt.wantStatus)
+			}
+			if len(report.Components) != len(tt.checks) {
+				t.Errorf("got %d components, want %d: %+v", len(report.Components), len(tt.checks), report.Components)
+			}
+		})
+	}
+	if deepRan {
+		t.Error("a deep check ran without ?deep")
+	}
+}
+
+func TestChecker_DeepCached(t *testing.T) {
+	runs := 0
+	c := &Checker{DeepTTL: time.Hour, Checks: []Check{{Name: "gemini", Deep: true, Run: func(context.Context) error {
+		runs++
+		return nil
+	}}}}
+	for range 3 {
+		if report := c.Run(context.Background(), true); report.Components["gemini"].Status != StatusOK {
+			t.Fatalf("gemini = %+v, want ok", report.Components["gemini"])
+		}
+	}
+	if runs != 1 {
+		t.Errorf("deep check ran %d times within its TTL, want 1", runs)
+	}
+
+	c.DeepTTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	c.Run(context.Background(), true)
+	if runs != 2 {
+		t.Errorf("deep check ran %d times after its TTL, want 2", runs)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := &Checker{Timeout: 10 * time.Millisecond, Checks: []Check{{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}}}
+	report := c.Run(context.Background(), false)
+	if got := report.Components["slow"]; got.Status != StatusFail || got.Error == "" {
+		t.Errorf("slow check = %+v, want a failure", got)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	if err := Reachable(srv.Client(), srv.URL+"/ok")(context.Background()); err != nil {
+		t.Errorf("Reachable(ok) = %v", err)
+	}
+	if err := Reachable(srv.Client(), srv.URL+"/broken")(context.Background()); err == nil {
+		t.Error("Reachable(broken) should fail on a 502")
+	}
+}
@@ -0,0 +1,170 @@
+// Package limits protects the web server from being hammered: a token bucket
+// rate limiter per caller and globally, and daily request and token quotas.
+package limits
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"golang.org/x/time/rate"
+)
+
+// idleBucketTTL is how long an unused caller bucket is kept before it is dropped
+const idleBucketTTL = 10 * time.Minute
+
+// Limiter is a token bucket rate limiter keyed by caller
+type Limiter struct {
+	perCaller  rate.Limit
+	burst      int
+	global     *rate.Limiter
+	trustProxy bool
+	now        func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewLimiter returns a limiter for cfg, or nil when rate limiting is disabled
+func NewLimiter(cfg config.Limits) *Limiter {
+	if cfg.RequestsPerMinute == 0 && cfg.GlobalRequestsPerMinute == 0 {
+		return nil
+	}
+	l := &Limiter{
+		perCaller:  perMinute(cfg.RequestsPerMinute),
+		burst:      max(cfg.Burst, 1),
+		trustProxy: cfg.TrustProxy,
+		now:        time.Now,
+		buckets:    map[string]*bucket{},
+	}
+	if cfg.GlobalRequestsPerMinute > 0 {
+		l.global = rate.NewLimiter(perMinute(cfg.GlobalRequestsPerMinute), max(cfg.Burst, int(math.Ceil(cfg.GlobalRequestsPerMinute/6))))
+	}
+	return l
+}
+
+func perMinute(n float64) rate.Limit {
+	if n == 0 {
+		return rate.Inf
+	}
+	return rate.Limit(n / 60)
+}
+
+// Wrap rate limits next. A nil Limiter lets every request through.
+func (l *Limiter) Wrap(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wait, ok := l.Allow(l.Key(r)); !ok {
+			TooManyRequests(w, wait, "Rate limit exceeded, please slow down")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow takes a token for key and from the global bucket. When either is
+// empty it returns false and how long to wait.
+func (l *Limiter) Allow(key string) (time.Duration, bool) {
+	now := l.now()
+	caller := l.bucket(key, now).ReserveN(now, 1)
+	if delay := caller.DelayFrom(now); !caller.OK() || delay > 0 {
+		caller.CancelAt(now)
+		return delay, false
+	}
+	if l.global != nil {
+		global := l.global.ReserveN(now, 1)
+		if delay := global.DelayFrom(now); !global.OK() || delay > 0 {
+			global.CancelAt(now)
+			caller.CancelAt(now)
+			return delay, false
+		}
+	}
+	return 0, true
+}
+
+// Key identifies the caller as CallerKey does
+func (l *Limiter) Key(r *http.Request) string {
+	return CallerKey(r, l.trustProxy)
+}
+
+// CallerKey identifies the caller for rate limits and quotas: the
+// authenticated user, whether signed in or by API key, or else the client IP
+func CallerKey(r *http.Request, trustProxy bool) string {
+	if u := auth.FromContext(r.Context()); u != nil {
+		return "user:" + u.ID
+	}
+	return "ip:" + ClientIP(r, trustProxy)
+}
+
+// Buckets returns the number of callers currently tracked
+func (l *Limiter) Buckets() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+func (l *Limiter) bucket(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleBucketTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.perCaller, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+// ClientIP returns the caller's IP address. With trustProxy the last
+// X-Forwarded-For entry, the one added by our own load balancer, is used.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			hops := strings.Split(fwd, ",")
+			return strings.TrimSpace(hops[len(hops)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// TooManyRequests writes a 429 response telling the client when to retry
+func TooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, fmt.Sprintf("%s. Retry after %d seconds.", message, seconds), http.StatusTooManyRequests)
+}
@@ -0,0 +1,141 @@
+package limits
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+func TestLimiter_PerCaller(t *testing.T) {
+	l := NewLimiter(config.Limits{RequestsPerMinute: 60, Burst: 2})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, ok := l.Allow("ip:1.1.1.1"); !ok {
+			t.Fatalf("request %d within burst was limited", i+1)
+		}
+	}
+	wait, ok := l.Allow("ip:1.1.1.1")
+	if ok || wait <= 0 || wait > time.Second {
+		t.Errorf("third request: ok=%v wait=%v, want limited for about a second", ok, wait)
+	}
+	if _, ok := l.Allow("ip:2.2.2.2"); !ok {
+		t.Error("another caller should have its own bucket")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := l.Allow("ip:1.1.1.1"); !ok {
+		t.Error("bucket should refill over time")
+	}
+}
+
+func TestLimiter_Global(t *testing.T) {
+	l := NewLimiter(config.Limits{RequestsPerMinute: 600, Burst: 1, GlobalRequestsPerMinute: 6})
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	if _, ok := l.Allow("a"); !ok {
+		t.Fatal("first request was limited")
+	}
+	if _, ok := l.Allow("b"); ok {
+		t.Error("global bucket should limit a second caller")
+	}
+}
+
+func TestLimiter_Wrap(t *testing.T) {
+	l := NewLimiter(config.Limits{RequestsPerMinute: 1, Burst: 1})
+	h := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/summarize", nil)
+		if user != "" {
+			req = req.WithContext(auth.WithUser(req.Context(), &auth.User{ID: user}))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send("alice"); rec.Code != http.StatusOK {
+		t.Fatalf("first request got %d", rec.Code)
+	}
+	rec := send("alice")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("second request got %d with Retry-After %q, want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := send("bob"); rec.Code != http.StatusOK {
+		t.Errorf("another user got %d, want their own bucket", rec.Code)
+	}
+
+	if NewLimiter(config.Limits{}) != nil {
+		t.Error("zero limits should disable the limiter")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 203.0.113.7")
+
+	if got := ClientIP(req, false); got != "10.0.0.1" {
+		t.Errorf("untrusted ClientIP = %q, want the remote address", got)
+	}
+	if got := ClientIP(req, true); got != "203.0.113.7" {
+		t.Errorf("trusted ClientIP = %q, want the last forwarded hop", got)
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "198.51.100.4:1234"
+	if got := CallerKey(req, false); got != "ip:198.51.100.4" {
+		t.Errorf("anonymous CallerKey = %q, want the client IP", got)
+	}
+	req = req.WithContext(auth.WithUser(req.Context(), &auth.User{ID: "alice", Method: "api_key"}))
+	if got := CallerKey(req, false); got != "user:alice" {
+		t.Errorf("authenticated CallerKey = %q, want the user", got)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	q := NewQuota(config.Limits{DailyRequests: 3, DailyTokens: 1000})
+	now := time.Date(2025, 1, 1, 18, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	if err := q.Reserve("alice", 2); err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	var exceeded *ExceededError
+	if err := q.Reserve("alice", 2); !errors.As(err, &exceeded) || exceeded.Reason != "request" {
+		t.Fatalf("Reserve() over the request quota error = %v", err)
+	}
+	if exceeded.RetryAfter != 6*time.Hour {
+		t.Errorf("RetryAfter = %v, want time until midnight UTC", exceeded.RetryAfter)
+	}
+
+	q.AddTokens("bob", 1200)
+	if err := q.Reserve("bob", 1); !errors.As(err, &exceeded) || exceeded.Reason != "token" {
+		t.Errorf("Reserve() over the token quota error = %v", err)
+	}
+
+	// A caller named like a wildcard is just another caller
+	q.AddTokens("*", 5)
+	snap := q.Snapshot()
+	if snap.Total.Requests != 2 || snap.Total.Tokens != 1205 || len(snap.Users) != 3 || snap.Users[0].User != "bob" || snap.Total.User != "" {
+		t.Errorf("Snapshot() = %+v", snap)
+	}
+
+	now = now.Add(7 * time.Hour)
+	if err := q.Reserve("alice", 3); err != nil {
+		t.Errorf("quota should reset the next day, got %v", err)
+	}
+	if len(q.usage) != 1 {
+		t.Errorf("%d callers tracked after the day rolled over, want only today's", len(q.usage))
+	}
+}
@@ -0,0 +1,142 @@
+package limits
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+// ExceededError reports an exhausted quota
+type ExceededError struct {
+	Reason string
+	// RetryAfter is the time until the quota resets
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("daily %s quota exceeded", e.Reason)
+}
+
+// Usage is what one user consumed today
+type Usage struct {
+	User        string    `json:"user"`
+	Requests    int       `json:"requests"`
+	Tokens      int64     `json:"tokens"`
+	LastRequest time.Time `json:"last_request"`
+}
+
+// Snapshot is the current consumption of every user
+type Snapshot struct {
+	Day    string  `json:"day"`
+	Total  Usage   `json:"total"`
+	Users  []Usage `json:"users"`
+	Limits struct {
+		DailyRequests     int   `json:"daily_requests"`
+		DailyTokens       int64 `json:"daily_tokens"`
+		GlobalDailyTokens int64 `json:"global_daily_tokens"`
+	} `json:"limits"`
+}
+
+// Quota accounts requests and tokens per user per UTC day. Counts are kept
+// in memory and start from zero when the server restarts.
+type Quota struct {
+	cfg config.Limits
+	now func() time.Time
+
+	mu    sync.Mutex
+	day   string
+	usage map[string]*Usage
+	// total is the deployment-wide consumption, kept apart so no user key
+	// can collide with it
+	total Usage
+}
+
+// NewQuota returns quota accounting for cfg. Usage is always recorded; limits
+// are only enforced when configured.
+func NewQuota(cfg config.Limits) *Quota {
+	return &Quota{cfg: cfg, now: time.Now, usage: map[string]*Usage{}}
+}
+
+// Reserve counts n requests for user, or returns an *ExceededError if they would exceed a quota
+func (q *Quota) Reserve(user string, n int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.rollover()
+	u, total := q.get(user), &q.total
+	switch {
+	case q.cfg.DailyRequests > 0 && u.Requests+n > q.cfg.DailyRequests:
+		return &ExceededError{Reason: "request", RetryAfter: untilMidnight(now)}
+	case q.cfg.DailyTokens > 0 && u.Tokens >= q.cfg.DailyTokens:
+		return &ExceededError{Reason: "token", RetryAfter: untilMidnight(now)}
+	case q.cfg.GlobalDailyTokens > 0 && total.Tokens >= q.cfg.GlobalDailyTokens:
+		return &ExceededError{Reason: "deployment token", RetryAfter: untilMidnight(now)}
+	}
+
+	for _, usage := range []*Usage{u, total} {
+		usage.Requests += n
+		usage.LastRequest = now
+	}
+	return nil
+}
+
+// AddTokens records tokens spent on behalf of user
+func (q *Quota) AddTokens(user string, tokens int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	q.get(user).Tokens += tokens
+	q.total.Tokens += tokens
+}
+
+// Snapshot returns today's consumption, heaviest token users first
+func (q *Quota) Snapshot() Snapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.rollover()
+	snap := Snapshot{Day: q.day, Total: q.total}
+	snap.Limits.DailyRequests = q.cfg.DailyRequests
+	snap.Limits.DailyTokens = q.cfg.DailyTokens
+	snap.Limits.GlobalDailyTokens = q.cfg.GlobalDailyTokens
+	for _, u := range q.usage {
+		snap.Users = append(snap.Users, *u)
+	}
+	sort.Slice(snap.Users, func(i, j int) bool {
+		if snap.Users[i].Tokens != snap.Users[j].Tokens {
+			return snap.Users[i].Tokens > snap.Users[j].Tokens
+		}
+		return snap.Users[i].User < snap.Users[j].User
+	})
+	return snap
+}
+
+// rollover starts a fresh day when the date has changed, dropping every
+// caller seen on earlier days. Callers hold q.mu.
+func (q *Quota) rollover() time.Time {
+	now := q.now().UTC()
+	if day := now.Format(time.DateOnly); day != q.day {
+		q.day = day
+		q.usage = map[string]*Usage{}
+		q.total = Usage{}
+	}
+	return now
+}
+
+func (q *Quota) get(user string) *Usage {
+	u, ok := q.usage[user]
+	if !ok {
+		u = &Usage{User: user}
+		q.usage[user] = u
+	}
+	return u
+}
+
+func untilMidnight(now time.Time) time.Duration {
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
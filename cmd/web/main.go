@@ -2,111 +2,409 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"html"
+	"log/slog"
 	"mime"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
-
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth"
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth/mockidp"
+	"github.com/BrunodsLilly/Summarizer/cmd/web/health"
+	"github.com/BrunodsLilly/Summarizer/cmd/web/limits"
+	"github.com/BrunodsLilly/Summarizer/cmd/web/static"
 	"github.com/BrunodsLilly/Summarizer/cmd/web/templates"
 	"github.com/BrunodsLilly/Summarizer/pkg/core"
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/renderer/html"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/logging"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	"github.com/a-h/templ"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// modelPolicy limits the models clients may request
+var modelPolicy *core.ModelPolicy
+
+// summaryStore persists generated summaries; nil when the store could not be opened
+var summaryStore *store.Store
+
+// rateLimiter throttles generation requests; nil when rate limiting is disabled
+var rateLimiter *limits.Limiter
+
+// quota accounts daily requests and tokens per user
+var quota *limits.Quota
+
+// trustProxy takes the client IP from X-Forwarded-For, as limits.trust_proxy says
+var trustProxy bool
+
+// factCheckCalls is the most Gemini calls one fact check makes: the extraction
+// and one verification per checked claim
+var factCheckCalls = 1
+
+// admins may view /admin/usage; everyone may when auth is disabled
+var admins []string
+
+// ready reports whether the server accepts new work; false while draining
+var ready atomic.Bool
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summarizer_http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.5, 1, 5, 15, 30, 60, 120, 300},
+	}, []string{"method", "route"})
 )
 
 func init() {
 	// Register MIME types for JavaScript
 	mime.AddExtensionType(".js", "application/javascript")
+
+	metrics.Registry.MustRegister(httpRequests, httpDuration)
 }
 
 func main() {
-	log.Println("Starting web server...")
+	flags := config.RegisterFlags(flag.CommandLine, true)
+	mockIDP := flag.Bool("mock-idp", false, "log browsers in through a local mock OIDC provider (development only)")
+	staticDir := flag.String("static-dir", "", "serve static assets from this directory instead of the embedded copy, e.g. cmd/web/static (development only)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [config show]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	// Get the directory where the executable is located
-	execDir, err := os.Executable()
+	cfg, err := flags.Load()
 	if err != nil {
-		log.Fatal("Failed to get executable directory:", err)
+		fatal("Invalid configuration", err)
 	}
-	webDir := filepath.Dir(execDir)
-
-	// For 'go run', determine the web directory based on where we're running from
-	if strings.Contains(execDir, "go-build") {
-		wd, _ := os.Getwd()
-		// Check if we're running from the parent directory (Summarizer)
-		if filepath.Base(wd) == "Summarizer" {
-			webDir = filepath.Join(wd, "cmd", "web")
-		} else {
-			// Assume we're running from cmd/web directory
-			webDir = wd
+	if flag.Arg(0) == "config" {
+		if flag.Arg(1) != "show" {
+			flag.Usage()
+			os.Exit(2)
 		}
+		if err := config.Show(os.Stdout, cfg); err != nil {
+			fatal("Failed to show configuration", err)
+		}
+		return
+	}
+	logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fatal("Invalid logging configuration", err)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Service:     "summarizer-web",
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if err := core.Configure(cfg); err != nil {
+		fatal("Failed to apply configuration", err)
+	}
+	modelPolicy = core.NewModelPolicy(cfg)
+	rateLimiter = limits.NewLimiter(cfg.Limits)
+	quota = limits.NewQuota(cfg.Limits)
+	trustProxy = cfg.Limits.TrustProxy
+	factCheckCalls = 1 + cfg.FactCheck.MaxClaims
+	if cfg.Auth.Enabled() {
+		// Non-nil even when empty so that no one is an admin by default
+		admins = append([]string{}, cfg.Auth.Admins...)
+	}
+
+	slog.Info("Starting web server")
+
+	assets := static.Embedded()
+	if *staticDir != "" {
+		slog.Warn("Serving static assets from disk", "dir", *staticDir)
+		assets = static.Dir(*staticDir)
+	}
+	static.Use(assets)
+
+	mux := http.NewServeMux()
+	mux.Handle(static.Prefix, assets)
+
+	summaryStore, err = core.OpenStore()
+	if err != nil {
+		slog.Warn("Summary store unavailable, summaries will not be saved", "error", err)
+	} else {
+		defer summaryStore.Close()
 	}
 
-	staticDir := filepath.Join(webDir, "static")
-	log.Printf("Web directory: %s", webDir)
-	log.Printf("Static directory: %s", staticDir)
+	// Warm the model catalog so the first page load doesn't wait on Models.List
+	go core.ListVideoModels(context.Background())
 
-	// Check if static directory exists
-	if _, err := os.Stat(staticDir); os.IsNotExist(err) {
-		log.Printf("Warning: Static directory does not exist: %s", staticDir)
-		log.Printf("Trying current directory as fallback...")
-		staticDir = "./static"
-		if _, err := os.Stat(staticDir); os.IsNotExist(err) {
-			log.Printf("Warning: Fallback static directory also not found: %s", staticDir)
+	// Application routes
+	mux.HandleFunc("/", indexHandler)
+	mux.Handle("/summarize", rateLimiter.Wrap(http.HandlerFunc(summarizeHandler)))
+	mux.Handle("/compare", rateLimiter.Wrap(http.HandlerFunc(compareHandler)))
+	mux.Handle("/extract", rateLimiter.Wrap(http.HandlerFunc(extractHandler)))
+	mux.Handle("/factcheck", rateLimiter.Wrap(http.HandlerFunc(factCheckHandler)))
+	mux.Handle("/search", rateLimiter.Wrap(http.HandlerFunc(searchHandler)))
+	mux.Handle("/api/search", rateLimiter.Wrap(http.HandlerFunc(apiSearchHandler)))
+	mux.HandleFunc("/summary", savedSummaryHandler)
+	mux.HandleFunc("/export", exportHandler)
+	mux.HandleFunc("/test-summary", testSummaryHandler)
+	mux.HandleFunc("/healthz", healthHandler)
+	mux.HandleFunc("/health", healthHandler)
+	mux.HandleFunc("/admin/usage", adminUsageHandler)
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
+	// The listen address honours PORT (for Cloud Run), SERVER_ADDR, the config file and -addr
+	addr := cfg.Server.Addr
+
+	if *mockIDP {
+		idp, err := startMockIDP(cfg)
+		if err != nil {
+			fatal("Failed to start mock OIDC provider", err)
 		}
+		defer idp.Close()
+	}
+	handler, err := setupAuth(mux, cfg)
+	if err != nil {
+		fatal("Failed to set up authentication", err)
+	}
+	mux.Handle("/readyz", readinessChecks(cfg))
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: tracing.Handler(withRequestLogging(handler), func(r *http.Request) string {
+			return routeLabel(r.URL.Path)
+		}),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+	}
+	slog.Info("Server starting", "addr", addr)
+	if err := serve(srv, time.Duration(cfg.Server.ShutdownTimeout)); err != nil {
+		fatal("Server stopped", err)
 	}
+	slog.Info("Server stopped")
+}
+
+// readHeaderTimeout bounds how long a client may take to send request headers
+const readHeaderTimeout = 10 * time.Second
 
-	// Create custom file server with proper MIME types
-	http.Handle("/static/", http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get the file path
-		filePath := r.URL.Path
-		log.Printf("Serving static file: %s", filePath)
+// serve runs srv until SIGINT or SIGTERM, then stops accepting connections,
+// reports not ready and gives in-flight requests up to drain to finish
+func serve(srv *http.Server, drain time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		// Determine MIME type from file extension
-		ext := filepath.Ext(filePath)
-		mimeType := mime.TypeByExtension(ext)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	ready.Store(true)
 
-		if mimeType != "" {
-			w.Header().Set("Content-Type", mimeType)
-			log.Printf("Set MIME type for %s file %s: %s", ext, filePath, mimeType)
-		} else if strings.HasSuffix(filePath, ".js") {
-			// Fallback for JavaScript files
-			w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
-			log.Printf("Fallback MIME type for JS file: %s", filePath)
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+	ready.Store(false)
+
+	slog.Info("Shutting down, draining in-flight requests", "timeout", drain)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("In-flight requests did not finish in time", "error", err)
+		return srv.Close()
+	}
+	return nil
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// statusRecorder captures the response status and size for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// withRequestLogging gives every request an ID, propagated through its
+// context into core and the Gemini calls, and writes one access log line per
+// request. A well-formed incoming X-Request-Id is kept.
+func withRequestLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = logging.NewRequestID()
 		}
+		w.Header().Set(logging.RequestIDHeader, id)
+		ctx := logging.WithRequestID(r.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("summarizer.request_id", id))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		route := routeLabel(r.URL.Path)
+		httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Inc()
+		httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case strings.HasPrefix(r.URL.Path, "/static/"), slices.Contains(probes, r.URL.Path):
+			level = slog.LevelDebug
+		}
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
 
-		// Serve the file using absolute path
-		fullPath := filepath.Join(staticDir, filePath)
-		log.Printf("Serving file from: %s", fullPath)
+// probes are polled by load balancers and orchestrators, so they only log at debug level
+var probes = []string{"/health", "/healthz", "/readyz"}
 
-		// Check if file exists
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-			log.Printf("File not found: %s", fullPath)
-			http.NotFound(w, r)
-			return
+// routes are the paths reported as metric labels; anything else is "other"
+// so that scanners can't blow up label cardinality
+var routes = []string{
+	"/", "/summarize", "/compare", "/extract", "/factcheck", "/search", "/api/search", "/summary", "/export",
+	"/test-summary", "/health", "/healthz", "/readyz", "/metrics", "/admin/usage", "/login", "/auth/callback", "/logout",
+}
+
+func routeLabel(path string) string {
+	if strings.HasPrefix(path, "/static/") {
+		return "/static/"
+	}
+	if slices.Contains(routes, path) {
+		return path
+	}
+	return "other"
+}
+
+// validRequestID accepts short IDs made of URL-safe characters so clients
+// can't inject arbitrary text into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
 		}
+	}
+	return true
+}
 
-		http.ServeFile(w, r, fullPath)
-	})))
+// setupAuth registers the login routes on mux and wraps it with the
+// configured authenticators. Without any configured, the server stays open.
+func setupAuth(mux *http.ServeMux, cfg *config.Config) (http.Handler, error) {
+	if !cfg.Auth.Enabled() {
+		slog.Warn("Authentication is disabled; anyone who can reach this server can use it")
+		return mux, nil
+	}
 
-	// Application routes
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/summarize", summarizeHandler)
-	http.HandleFunc("/test-summary", testSummaryHandler)
-	http.HandleFunc("/health", healthHandler)
+	secret := []byte(cfg.Auth.SessionSecret)
+	if len(secret) == 0 {
+		slog.Warn("No session secret configured; sessions will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	sessions := auth.NewSessions(secret, time.Duration(cfg.Auth.SessionTTL))
 
-	// Get port from environment variable (for Cloud Run) or default to 8080
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	mw := &auth.Middleware{Public: []string{"/health", "/healthz", "/readyz", "/metrics", "/static/"}}
+	if len(cfg.Auth.APIKeys) > 0 {
+		keys := make(map[string]string, len(cfg.Auth.APIKeys))
+		for user, key := range cfg.Auth.APIKeys {
+			keys[user] = string(key)
+		}
+		mw.Authenticators = append(mw.Authenticators, auth.NewAPIKeys(keys))
 	}
+	if cfg.Auth.OIDC.Issuer != "" {
+		provider, err := auth.NewOIDC(context.Background(), cfg.Auth.OIDC, sessions)
+		if err != nil {
+			return nil, err
+		}
+		mux.HandleFunc("/login", provider.LoginHandler)
+		mux.HandleFunc("/auth/callback", provider.CallbackHandler)
+		mux.HandleFunc("/logout", provider.LogoutHandler)
+		mw.Authenticators = append(mw.Authenticators, sessions)
+		mw.LoginURL = "/login"
+		mw.Public = append(mw.Public, "/login", "/auth/callback")
+	}
+	return mw.Handler(mux), nil
+}
 
-	fmt.Printf("Server starting on port %s\n", port)
-	fmt.Printf("Static files served from: %s\n", staticDir)
-	fmt.Printf("Test summary available at http://localhost:%s/test-summary\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+// startMockIDP runs a local OIDC provider and points the config at it
+func startMockIDP(cfg *config.Config) (*mockidp.Server, error) {
+	idp, err := mockidp.Start("127.0.0.1:0", "summarizer-dev", mockidp.DefaultUser)
+	if err != nil {
+		return nil, err
+	}
+	host := cfg.Server.Addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	cfg.Auth.OIDC = config.OIDC{
+		Issuer:      idp.URL,
+		ClientID:    idp.ClientID,
+		RedirectURL: "http://" + host + "/auth/callback",
+	}
+	slog.Warn("Mock OIDC provider running; every login is the same user", "issuer", idp.URL, "user", idp.User.Email)
+	return idp, nil
 }
 
 func indexHandler(w http.ResponseWriter, r *http.Request) {
@@ -115,85 +413,465 @@ func indexHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	component := templates.Index()
+	component := templates.Index(modelPolicy.Models(r.Context()), modelPolicy.Default)
 	err := component.Render(r.Context(), w)
 	if err != nil {
 		http.Error(w, "Failed to render template", http.StatusInternalServerError)
-		log.Printf("Template rendering error: %v", err)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
 		return
 	}
 }
 
 func summarizeHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseJobForm(w, r)
+	if !ok {
+		return
+	}
+
+	if !reserveQuota(w, r, 1) {
+		return
+	}
+
+	// Generate summary with selected model
+	slog.InfoContext(r.Context(), "Summarize requested", "user", auth.UserID(r.Context()), "model", req.Model, "url", req.URL, "input", req.Input)
+	summary, usage, id := generateSummaryWithModel(r.Context(), req)
+	if usage != nil {
+		quota.AddTokens(quotaKey(r), int64(usage.TotalTokens))
+	}
+
+	component := templates.SummaryResult(summary, usage, id)
+	err := component.Render(r.Context(), w)
+	if err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// maxCompareModels bounds how many concurrent generations one compare request may start
+const maxCompareModels = 4
+
+func compareHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseJobForm(w, r)
+	if !ok {
+		return
+	}
+
+	var models []string
+	for _, requested := range r.Form["models"] {
+		model, err := resolveModel(w, r, requested)
+		if err != nil {
+			return
+		}
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	if len(models) < 2 || len(models) > maxCompareModels {
+		http.Error(w, fmt.Sprintf("Select between 2 and %d models to compare", maxCompareModels), http.StatusBadRequest)
+		return
+	}
+
+	if !reserveQuota(w, r, len(models)) {
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Compare requested", "user", auth.UserID(r.Context()), "models", models, "url", req.URL, "input", req.Input)
+	comparisons := core.Compare(r.Context(), req, models)
+
+	columns := make([]templates.ComparedSummary, len(comparisons))
+	for i, c := range comparisons {
+		column := templates.ComparedSummary{Model: c.Model}
+		if c.Err != nil {
+			slog.ErrorContext(r.Context(), "Summarization failed", "model", c.Model, "url", req.URL, "error", c.Err)
+			column.Error = c.Err.Error()
+		} else {
+			quota.AddTokens(quotaKey(r), int64(c.Result.Usage.TotalTokens))
+			column.HTML = markdownToHTML(c.Result.Text)
+			column.Latency = c.Result.Latency
+			column.Usage = c.Result.Usage
+			column.ID = saveSummary(r.Context(), req.URL, c.Model, c.Result.Text)
+		}
+		columns[i] = column
+	}
+
+	component := templates.CompareResult(columns)
+	err := component.Render(r.Context(), w)
+	if err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+func extractHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseJobForm(w, r)
+	if !ok {
+		return
+	}
+
+	if !reserveQuota(w, r, 1) {
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Extraction requested", "user", auth.UserID(r.Context()), "model", req.Model, "url", req.URL, "input", req.Input)
+	var result templates.ExtractedStatements
+	extraction, err := core.Extract(r.Context(), req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Extraction failed", "model", req.Model, "url", req.URL, "error", err)
+		result.Error = fmt.Sprintf("Error extracting quotes and claims for URL: %s using model %s\n%s", req.URL, req.Model, err)
+	} else {
+		quota.AddTokens(quotaKey(r), int64(extraction.Usage.TotalTokens))
+		result.Extraction = extraction
+		result.Downloads = statementDownloads(extraction)
+	}
+
+	component := templates.StatementsResult(result)
+	err = component.Render(r.Context(), w)
+	if err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// statementDownloads encodes an extraction in every statement format
+func statementDownloads(e *core.Extraction) []templates.StatementDownload {
+	var downloads []templates.StatementDownload
+	for _, f := range export.StatementFormats() {
+		var buf bytes.Buffer
+		if err := export.WriteStatements(&buf, f, e); err != nil {
+			continue
+		}
+		downloads = append(downloads, templates.StatementDownload{
+			Label:    f.Label(),
+			Filename: export.StatementsFilename(e, f),
+			URL:      templ.SafeURL("data:" + f.ContentType() + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes())),
+		})
+	}
+	return downloads
+}
+
+func factCheckHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseJobForm(w, r)
+	if !ok {
+		return
+	}
+
+	if !reserveQuota(w, r, factCheckCalls) {
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Fact check requested", "user", auth.UserID(r.Context()), "model", req.Model, "url", req.URL, "input", req.Input)
+	var report templates.FactCheckReport
+	result, err := core.FactCheck(r.Context(), req, nil)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Fact check failed", "model", req.Model, "url", req.URL, "error", err)
+		report.Error = fmt.Sprintf("Error fact-checking URL: %s using model %s\n%s", req.URL, req.Model, err)
+	} else {
+		slog.InfoContext(r.Context(), "Fact check done", "model", req.Model, "url", req.URL, "claims", result.Claims, "checked", len(result.Checks), "tokens", result.Usage.TotalTokens)
+		quota.AddTokens(quotaKey(r), int64(result.Usage.TotalTokens))
+		report.FactCheckResult = result
+		report.Downloads = factCheckDownloads(result)
+	}
+
+	component := templates.FactCheckSection(report)
+	err = component.Render(r.Context(), w)
+	if err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// factCheckDownloads encodes a fact check in every fact check format
+func factCheckDownloads(result *core.FactCheckResult) []templates.StatementDownload {
+	var downloads []templates.StatementDownload
+	for _, f := range export.FactCheckFormats() {
+		var buf bytes.Buffer
+		if err := export.WriteFactCheck(&buf, f, result); err != nil {
+			continue
+		}
+		downloads = append(downloads, templates.StatementDownload{
+			Label:    f.Label(),
+			Filename: export.FactCheckFilename(result, f),
+			URL:      templ.SafeURL("data:" + f.ContentType() + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes())),
+		})
+	}
+	return downloads
+}
+
+// parseJobForm reads the request shared by the generation endpoints: the URL,
+// model, input, clip and generation settings. On failure it writes the error
+// response and returns false.
+func parseJobForm(w http.ResponseWriter, r *http.Request) (core.Request, bool) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+		return core.Request{}, false
 	}
 
 	err := r.ParseForm()
 	if err != nil {
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
-		return
+		return core.Request{}, false
 	}
 
 	url := r.FormValue("url")
 	if strings.TrimSpace(url) == "" {
 		http.Error(w, "Text is required", http.StatusBadRequest)
-		return
+		return core.Request{}, false
 	}
 
-	selectedModel := r.FormValue("model")
-	if strings.TrimSpace(selectedModel) == "" {
-		selectedModel = "gemini-2.5-pro-preview-05-06" // Default model
+	selectedModel, err := resolveModel(w, r, r.FormValue("model"))
+	if err != nil {
+		return core.Request{}, false
 	}
 
-	// Generate summary with selected model
-	summary := generateSummaryWithModel(url, selectedModel)
+	generation, err := generationFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return core.Request{}, false
+	}
+	input, languages, err := inputFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return core.Request{}, false
+	}
+	start, end, err := clipFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return core.Request{}, false
+	}
 
-	component := templates.SummaryResult(summary)
-	err = component.Render(r.Context(), w)
+	return core.Request{
+		URL:        url,
+		Model:      selectedModel,
+		Input:      input,
+		Languages:  languages,
+		Start:      start,
+		End:        end,
+		Chunked:    r.FormValue("chunked") != "",
+		Generation: generation,
+	}, true
+}
+
+// resolveModel applies the deployment's model policy to a requested model or
+// alias. On failure it writes the error response and returns a non-nil error.
+func resolveModel(w http.ResponseWriter, r *http.Request, requested string) (string, error) {
+	model, err := modelPolicy.Resolve(r.Context(), requested)
+	var notAllowed *core.ModelNotAllowedError
+	switch {
+	case errors.As(err, &notAllowed):
+		http.Error(w, fmt.Sprintf("Model %q is not allowed. Allowed values: %s", notAllowed.Requested, strings.Join(notAllowed.Allowed, ", ")), http.StatusBadRequest)
+	case err != nil:
+		slog.ErrorContext(r.Context(), "Model policy error", "error", err)
+		http.Error(w, "No model is available", http.StatusInternalServerError)
+	}
+	return model, err
+}
+
+// quotaKey identifies the caller for quotas the way the rate limiter does, so
+// that anonymous visitors do not share one quota
+func quotaKey(r *http.Request) string {
+	return limits.CallerKey(r, trustProxy)
+}
+
+// reserveQuota counts n generations against the caller's daily quota. When the
+// quota is used up it writes a 429 and returns false.
+func reserveQuota(w http.ResponseWriter, r *http.Request, n int) bool {
+	err := quota.Reserve(quotaKey(r), n)
+	var exceeded *limits.ExceededError
+	if errors.As(err, &exceeded) {
+		limits.TooManyRequests(w, exceeded.RetryAfter, fmt.Sprintf("Daily %s quota exceeded", exceeded.Reason))
+		return false
+	}
+	return true
+}
+
+func adminUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if admins != nil && !slices.Contains(admins, auth.UserID(r.Context())) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	snap := quota.Snapshot()
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snap)
+		return
+	}
+
+	component := templates.AdminUsage(snap, rateLimiter.Buckets())
+	err := component.Render(r.Context(), w)
 	if err != nil {
 		http.Error(w, "Failed to render template", http.StatusInternalServerError)
-		log.Printf("Template rendering error: %v", err)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
 		return
 	}
 }
 
-func generateSummary(url string) string {
-	res, err := core.SummarizeURL(url)
+// generateSummaryWithModel returns the rendered summary and its store ID (0 if unsaved)
+func generateSummaryWithModel(ctx context.Context, req core.Request) (*render.Document, *core.Usage, int64) {
+	if req.Chunked {
+		req.Progress = func(p core.Progress) {
+			if p.Done {
+				slog.InfoContext(ctx, "Chunked summary progress", "model", req.Model, "url", req.URL, "stage", p.Stage, "segment", p.Segment, "segments", p.Segments, "error", p.Err)
+			}
+		}
+	}
+	res, err := core.Summarize(ctx, req)
 	if err != nil {
-		log.Printf("Error summarizing URL: %v", err)
-		// return "Error generating summary"
-		return fmt.Sprintf("Error generating summary for URL: %s\n%s", url, err.Error())
+		slog.ErrorContext(ctx, "Summarization failed", "model", req.Model, "url", req.URL, "error", err)
+		// The result is rendered as raw HTML and the URL is user input
+		return &render.Document{HTML: html.EscapeString(fmt.Sprintf("Error generating summary for URL: %s using model %s\n%s", req.URL, req.Model, err.Error()))}, nil, 0
+	}
+	slog.InfoContext(ctx, "Summary generated", "model", req.Model, "url", req.URL, "total_tokens", res.Usage.TotalTokens, "video_tokens", res.Usage.VideoTokens, "audio_tokens", res.Usage.AudioTokens)
+	if res.Fallback != "" {
+		slog.InfoContext(ctx, "Summarized from the transcript", "model", req.Model, "url", req.URL, "reason", res.Fallback)
 	}
-	return markdownToHTML(res)
+	id := saveSummary(ctx, req.URL, req.Model, res.Text)
+
+	_, span := tracing.Start(ctx, "web.RenderMarkdown")
+	defer span.End()
+	return renderSummary(res.Text), &res.Usage, id
 }
 
-func generateSummaryWithModel(url, modelName string) string {
-	res, err := core.SummarizeURLWithModel(url, modelName)
-	if err != nil {
-		log.Printf("Error summarizing URL with model %s: %v", modelName, err)
-		return fmt.Sprintf("Error generating summary for URL: %s using model %s\n%s", url, modelName, err.Error())
+// inputFromForm reads the optional input mode and transcript languages of the
+// summarize form. Empty fields keep the configured defaults.
+func inputFromForm(r *http.Request) (string, []string, error) {
+	input := strings.TrimSpace(r.FormValue("input"))
+	if input != "" && input != config.InputVideo && input != config.InputTranscript {
+		return "", nil, fmt.Errorf("input must be %s or %s", config.InputVideo, config.InputTranscript)
 	}
-	return markdownToHTML(res)
+	var languages []string
+	for _, lang := range strings.Split(r.FormValue("lang"), ",") {
+		if lang = strings.TrimSpace(lang); lang != "" {
+			languages = append(languages, lang)
+		}
+	}
+	return input, languages, nil
 }
 
-func markdownToHTML(markdown string) string {
-	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
-	)
+// clipFromForm reads the optional time range of the summarize form. Empty
+// fields are zero: the URL's t= parameter and the end of the video.
+func clipFromForm(r *http.Request) (time.Duration, time.Duration, error) {
+	var offsets [2]time.Duration
+	for i, field := range []string{"start", "end"} {
+		value := strings.TrimSpace(r.FormValue(field))
+		if value == "" {
+			continue
+		}
+		d, err := transcript.ParseTimestamp(value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", field, err)
+		}
+		offsets[i] = d
+	}
+	return offsets[0], offsets[1], nil
+}
 
-	var buf bytes.Buffer
-	if err := md.Convert([]byte(markdown), &buf); err != nil {
-		log.Printf("Error converting markdown to HTML: %v", err)
-		return markdown // fallback to original text
+// generationFromForm reads the optional "Advanced" settings of the summarize
+// form. Empty fields keep the configured defaults.
+func generationFromForm(r *http.Request) (core.GenerationOptions, error) {
+	var gen core.GenerationOptions
+	var errs []error
+
+	parseFloat := func(field string) *float32 {
+		value := strings.TrimSpace(r.FormValue(field))
+		if value == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number", field))
+			return nil
+		}
+		v := float32(f)
+		return &v
 	}
+	parseInt := func(field string) *int32 {
+		value := strings.TrimSpace(r.FormValue(field))
+		if value == "" {
+			return nil
+		}
+		i, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be an integer", field))
+			return nil
+		}
+		v := int32(i)
+		return &v
+	}
+
+	gen.Temperature = parseFloat("temperature")
+	gen.TopP = parseFloat("top_p")
+	gen.TopK = parseFloat("top_k")
+	gen.Seed = parseInt("seed")
+	gen.ThinkingBudget = parseInt("thinking_budget")
+	if max := parseInt("max_output_tokens"); max != nil {
+		gen.MaxOutputTokens = *max
+	}
+	if stop := strings.TrimSpace(r.FormValue("stop_sequences")); stop != "" {
+		for _, s := range strings.Split(stop, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				gen.StopSequences = append(gen.StopSequences, s)
+			}
+		}
+	}
+	if safety := strings.TrimSpace(r.FormValue("safety")); safety != "" {
+		gen.Safety = config.ParseSafety(safety)
+	}
+	if fps := strings.TrimSpace(r.FormValue("fps")); fps != "" {
+		f, err := strconv.ParseFloat(fps, 64)
+		if err != nil {
+			errs = append(errs, errors.New("fps must be a number"))
+		} else {
+			gen.FPS = &f
+		}
+	}
+	gen.MediaResolution = strings.TrimSpace(r.FormValue("media_resolution"))
 
-	return buf.String()
+	if err := errors.Join(errs...); err != nil {
+		return gen, err
+	}
+	return gen, gen.Validate()
+}
+
+// saveSummary persists a summary for later search and export, attributed to
+// the requesting user; failures are logged only
+func saveSummary(ctx context.Context, url, modelName, markdown string) int64 {
+	if summaryStore == nil {
+		return 0
+	}
+	ctx, span := tracing.Start(ctx, "store.Save")
+	summary := &store.Summary{URL: url, Model: modelName, User: auth.UserID(ctx), Content: markdown}
+	err := summaryStore.Save(context.WithoutCancel(ctx), summary)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save summary", "url", url, "error", err)
+	}
+	tracing.End(span, err)
+	return summary.ID
+}
+
+// renderSummary renders model output to sanitized HTML, safe to embed raw,
+// along with its reading stats
+func renderSummary(markdown string) *render.Document {
+	doc, err := render.Render(markdown, render.Options{})
+	if err != nil {
+		slog.Error("Failed to convert markdown to HTML", "error", err)
+		doc = render.Analyze(markdown)
+		doc.HTML = "<pre>" + html.EscapeString(markdown) + "</pre>" // fallback to original text
+	}
+	return doc
+}
+
+// markdownToHTML renders model output to sanitized HTML, safe to embed raw
+func markdownToHTML(markdown string) string {
+	return renderSummary(markdown).HTML
 }
 
 func testSummaryHandler(w http.ResponseWriter, r *http.Request) {
@@ -307,19 +985,236 @@ func testSummaryHandler(w http.ResponseWriter, r *http.Request) {
 	*This summary provides a comprehensive overview of artificial intelligence, covering technical aspects, applications, challenges, and future directions. The content is designed to be informative yet accessible to a broad audience.*`
 
 	// Convert markdown to HTML
-	htmlSummary := markdownToHTML(sampleSummary)
+	htmlSummary := renderSummary(sampleSummary)
 
 	// Render the test page with full layout
 	component := templates.TestSummaryPage(htmlSummary)
 	err := component.Render(r.Context(), w)
 	if err != nil {
 		http.Error(w, "Failed to render template", http.StatusInternalServerError)
-		log.Printf("Template rendering error: %v", err)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// searchQuery reads the shared search parameters from a request
+func searchQuery(r *http.Request) store.Query {
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	return store.Query{
+		Text:     r.FormValue("q"),
+		Semantic: r.FormValue("mode") == "semantic",
+		Limit:    limit,
+		User:     auth.UserID(r.Context()),
+	}
+}
+
+// reserveSearch counts a semantic search against the daily quota, since
+// embedding the query calls Gemini. Full-text searches are free.
+func reserveSearch(w http.ResponseWriter, r *http.Request, q store.Query) bool {
+	if !q.Semantic || strings.TrimSpace(q.Text) == "" {
+		return true
+	}
+	return reserveQuota(w, r, 1)
+}
+
+// searchHandler renders search results as an HTML fragment for the search box
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if summaryStore == nil {
+		http.Error(w, "Search is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := searchQuery(r)
+	if !reserveSearch(w, r, query) {
+		return
+	}
+	results, err := summaryStore.Search(r.Context(), query)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Search failed", "error", err)
+		if errors.Is(err, store.ErrNoEmbedder) {
+			http.Error(w, "Semantic search is not configured", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+		return
+	}
+
+	component := templates.SearchResults(query.Text, results)
+	if err := component.Render(r.Context(), w); err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// apiSearchHandler serves search results as JSON for programmatic clients
+func apiSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if summaryStore == nil {
+		http.Error(w, "Search is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := searchQuery(r)
+	if strings.TrimSpace(query.Text) == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+	if !reserveSearch(w, r, query) {
+		return
+	}
+
+	results, err := summaryStore.Search(r.Context(), query)
+	if errors.Is(err, store.ErrNoEmbedder) {
+		http.Error(w, "Semantic search is not configured", http.StatusBadRequest)
 		return
 	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Search failed", "error", err)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+		return
+	}
+	if results == nil {
+		results = []store.Result{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"query": query.Text, "results": results})
 }
 
+// savedSummaryHandler renders a previously saved summary
+func savedSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if summaryStore == nil {
+		http.Error(w, "Summary store is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid summary id", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := summaryStore.Get(r.Context(), id, auth.UserID(r.Context()))
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to load summary", "id", id, "error", err)
+		http.Error(w, "Failed to load summary", http.StatusInternalServerError)
+		return
+	}
+
+	component := templates.SummaryResult(renderSummary(summary.Content), nil, summary.ID)
+	if err := component.Render(r.Context(), w); err != nil {
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "Template rendering failed", "error", err)
+		return
+	}
+}
+
+// exportHandler downloads a saved summary in the requested format
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if summaryStore == nil {
+		http.Error(w, "Summary store is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid summary id", http.StatusBadRequest)
+		return
+	}
+	format, err := export.ParseFormat(r.FormValue("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := summaryStore.Get(r.Context(), id, auth.UserID(r.Context()))
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to load summary", "id", id, "error", err)
+		http.Error(w, "Failed to load summary", http.StatusInternalServerError)
+		return
+	}
+
+	// Render fully before writing so a failure can still become an error response
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, summary); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to export summary", "id", id, "format", format, "error", err)
+		http.Error(w, "Failed to export summary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename(summary, format)}))
+	w.Write(buf.Bytes())
+}
+
+// healthHandler is the liveness probe: the process is up and serving
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
+
+// readinessChecks builds the /readyz probe. It fails while the server is
+// starting or draining, so load balancers stop sending new requests, and when
+// a dependency needed to summarize is missing. /readyz?deep=1 also calls
+// Gemini, at most once per health.Checker DeepTTL however often it is polled.
+func readinessChecks(cfg *config.Config) *health.Checker {
+	checks := []health.Check{
+		{Name: "server", Run: func(context.Context) error {
+			if !ready.Load() {
+				return errors.New("not serving: starting or shutting down")
+			}
+			return nil
+		}},
+		{Name: "credentials", Run: func(context.Context) error { return core.CheckCredentials() }},
+		{Name: "gemini", Deep: true, Run: func(ctx context.Context) error {
+			// The probe is public and genai errors can quote the client config,
+			// so only the error class is reported
+			if err := core.CheckGemini(ctx); err != nil {
+				slog.WarnContext(ctx, "Gemini readiness check failed", "error", err)
+				return fmt.Errorf("listing models failed: %s", metrics.ErrorClass(err))
+			}
+			return nil
+		}},
+		// Summaries are still served without the store, just not saved
+		{Name: "store", Optional: true, Run: func(ctx context.Context) error {
+			if summaryStore == nil {
+				return errors.New("store could not be opened at startup")
+			}
+			return summaryStore.Ping(ctx)
+		}},
+	}
+	if cfg.Auth.OIDC.Issuer != "" {
+		checks = append(checks, health.Check{
+			Name: "oidc",
+			Run:  health.Reachable(http.DefaultClient, strings.TrimSuffix(cfg.Auth.OIDC.Issuer, "/")+"/.well-known/openid-configuration"),
+		})
+	}
+	if cfg.Tracing.Exporter == tracing.ExporterOTLP && cfg.Tracing.Endpoint != "" {
+		checks = append(checks, health.Check{Name: "tracing", Optional: true, Run: health.Reachable(http.DefaultClient, cfg.Tracing.Endpoint)})
+	}
+	return &health.Checker{Checks: checks}
+}
@@ -19,10 +19,15 @@ function initializeReadingProgress() {
         return;
     }
     
-    // Calculate word count
+    // Prefer the word count computed by the server from the Markdown source
     const text = content.textContent || content.innerText;
-    const words = text.trim().split(/\s+/).filter(word => word.length > 0);
-    totalWords = words.length;
+    const serverWords = parseInt(content.dataset.words, 10);
+    if (serverWords > 0) {
+        totalWords = serverWords;
+    } else {
+        const words = text.trim().split(/\s+/).filter(word => word.length > 0);
+        totalWords = words.length;
+    }
     
     console.log('Reading progress initialized:', {
         contentFound: !!content,
@@ -0,0 +1,144 @@
+// Package static serves the web server's JavaScript and CSS. The files are
+// embedded into the binary and linked under content-hashed URLs so browsers
+// can cache them forever; a development mode serves them from disk instead.
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed js
+var embedded embed.FS
+
+// Prefix is the URL path the assets are served under
+const Prefix = "/static/"
+
+// hashLen is the number of hex digits of the content hash put into URLs
+const hashLen = 10
+
+// Assets is a tree of static files served by content hash
+type Assets struct {
+	fsys fs.FS
+	dev  bool
+
+	mu     sync.Mutex
+	hashes map[string]string
+}
+
+// Embedded returns the assets compiled into the binary
+func Embedded() *Assets {
+	return &Assets{fsys: embedded, hashes: map[string]string{}}
+}
+
+// Dir returns the assets in dir, re-read on every request so that edits show
+// up on reload without rebuilding
+func Dir(dir string) *Assets {
+	return &Assets{fsys: os.DirFS(dir), dev: true}
+}
+
+var active = Embedded()
+
+// Use selects the assets that URL links to; call it before serving
+func Use(a *Assets) {
+	active = a
+}
+
+// URL returns the URL of the named asset in the assets passed to Use, e.g.
+// URL("js/app.js") is "/static/js/app.0123456789.js"
+func URL(name string) string {
+	return active.URL(name)
+}
+
+// URL returns the content-hashed URL of the named asset. Unknown assets get
+// their plain URL, which still works but is not cached.
+func (a *Assets) URL(name string) string {
+	hash, err := a.hash(name)
+	if err != nil {
+		return Prefix + name
+	}
+	ext := path.Ext(name)
+	return Prefix + strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// ServeHTTP serves requests for Prefix + name or the hashed URL of name.
+// Hashed URLs are immutable; plain URLs and dev mode revalidate with the ETag.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, requested := splitHash(strings.TrimPrefix(r.URL.Path, Prefix))
+	if !fs.ValidPath(name) || path.Ext(name) == ".go" {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	hash := a.remember(name, data)
+
+	w.Header().Set("ETag", `"`+hash+`"`)
+	if requested == hash && !a.dev {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+// hash returns the content hash of name, cached unless in dev mode
+func (a *Assets) hash(name string) (string, error) {
+	if !a.dev {
+		a.mu.Lock()
+		hash, ok := a.hashes[name]
+		a.mu.Unlock()
+		if ok {
+			return hash, nil
+		}
+	}
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return "", err
+	}
+	return a.remember(name, data), nil
+}
+
+func (a *Assets) remember(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:hashLen]
+	if !a.dev {
+		a.mu.Lock()
+		a.hashes[name] = hash
+		a.mu.Unlock()
+	}
+	return hash
+}
+
+// splitHash turns "js/app.0123456789.js" into "js/app.js" and the hash.
+// Names without a hash are returned unchanged.
+func splitHash(name string) (string, string) {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	dot := strings.LastIndexByte(stem, '.')
+	if dot < 0 || len(stem)-dot-1 != hashLen {
+		return name, ""
+	}
+	hash := stem[dot+1:]
+	if _, err := hex.DecodeString(hash); err != nil {
+		return name, ""
+	}
+	return stem[:dot] + ext, hash
+}
@@ -0,0 +1,90 @@
+package static
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, a *Assets, url string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEmbedded_HashedURLs(t *testing.T) {
+	a := Embedded()
+	url := a.URL("js/reader-controls.js")
+	if !strings.HasPrefix(url, "/static/js/reader-controls.") || url == "/static/js/reader-controls.js" {
+		t.Fatalf("URL() = %q, want a hashed URL", url)
+	}
+
+	rec := get(t, a, url)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Fatalf("hashed URL: %d, Cache-Control %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if rec := get(t, a, url, "If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: %d, want 304", rec.Code)
+	}
+
+	rec = get(t, a, "/static/js/reader-controls.js")
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-cache" || rec.Header().Get("ETag") != etag {
+		t.Errorf("plain URL: %d, Cache-Control %q, ETag %q", rec.Code, rec.Header().Get("Cache-Control"), rec.Header().Get("ETag"))
+	}
+
+	for _, bad := range []string{"/static/js/missing.js", "/static/../main.go", "/static/static.go"} {
+		if rec := get(t, a, bad); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: %d, want 404", bad, rec.Code)
+		}
+	}
+}
+
+func TestDir_PicksUpEdits(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.js")
+	os.WriteFile(file, []byte("one"), 0o644)
+	os.WriteFile(filepath.Join(dir, "static.go"), []byte("package static"), 0o644)
+
+	a := Dir(dir)
+	before := a.URL("app.js")
+	os.WriteFile(file, []byte("two"), 0o644)
+	after := a.URL("app.js")
+	if before == after {
+		t.Errorf("URL() did not change after an edit: %q", after)
+	}
+
+	rec := get(t, a, after)
+	if rec.Body.String() != "two" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("dev mode served %q with Cache-Control %q", rec.Body.String(), rec.Header().Get("Cache-Control"))
+	}
+	if rec := get(t, a, "/static/static.go"); rec.Code != http.StatusNotFound {
+		t.Errorf("Go sources should not be served in dev mode, got %d", rec.Code)
+	}
+}
+
+func TestSplitHash(t *testing.T) {
+	tests := []struct{ in, name, hash string }{
+		{"js/app.0123456789.js", "js/app.js", "0123456789"},
+		{"js/app.js", "js/app.js", ""},
+		{"js/jquery.min.js", "js/jquery.min.js", ""},
+		{"js/app.zzzzzzzzzz.js", "js/app.zzzzzzzzzz.js", ""},
+	}
+	for _, tt := range tests {
+		if name, hash := splitHash(tt.in); name != tt.name || hash != tt.hash {
+			t.Errorf("splitHash(%q) = %q, %q; want %q, %q", tt.in, name, hash, tt.name, tt.hash)
+		}
+	}
+}
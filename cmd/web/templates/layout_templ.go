@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.865
+// templ: version: v0.3.887
 package templates
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -8,6 +8,12 @@ package templates
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import (
+	"github.com/BrunodsLilly/Summarizer/cmd/web/auth"
+	"github.com/BrunodsLilly/Summarizer/cmd/web/static"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+)
+
 func Layout(title string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -36,13 +42,25 @@ func Layout(title string) templ.Component {
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(title)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 9, Col: 16}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 15, Col: 16}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><script src=\"https://cdn.tailwindcss.com\"></script><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><style>\n\t\t\t/* Dark mode reader styles */\n\t\t\t.reader-content {\n\t\t\t\tfont-family: 'Georgia', 'Times New Roman', serif;\n\t\t\t\tline-height: 1.8;\n\t\t\t\tmax-width: 65ch;\n\t\t\t\tmargin: 0 auto;\n\t\t\t}\n\t\t\t\n\t\t\t.reader-content h1 { @apply text-3xl font-bold mt-8 mb-4 pb-3 border-b border-gray-600 text-gray-100; }\n\t\t\t.reader-content h2 { @apply text-2xl font-semibold mt-6 mb-3 text-gray-200; }\n\t\t\t.reader-content h3 { @apply text-xl font-medium mt-5 mb-2 text-gray-200; }\n\t\t\t.reader-content p { @apply leading-relaxed mb-6 text-gray-300 text-lg; }\n\t\t\t.reader-content ul, .reader-content ol { @apply mb-6 pl-8 text-gray-300; }\n\t\t\t.reader-content li { @apply mb-3 leading-relaxed; }\n\t\t\t.reader-content code { @apply bg-gray-800 text-green-400 px-2 py-1 rounded text-sm font-mono; }\n\t\t\t.reader-content pre { @apply bg-gray-800 text-green-400 p-4 rounded-lg overflow-x-auto border border-gray-700; }\n\t\t\t.reader-content blockquote { @apply border-l-4 border-blue-500 my-6 pl-6 text-gray-400 italic bg-gray-800 py-4 rounded-r; }\n\t\t\t.reader-content strong { @apply font-bold text-white; }\n\t\t\t.reader-content em { @apply italic text-blue-300; }\n\t\t\t\n\t\t\t/* Bionic text styles */\n\t\t\t.bionic { font-weight: 600; color: #ffffff; }\n\t\t\t.bionic-word { display: inline; }\n\t\t\t.non-bionic { font-weight: 400; color: #9ca3af; }\n\t\t\t\n\t\t\t/* Reader controls */\n\t\t\t.reader-controls {\n\t\t\t\tposition: sticky;\n\t\t\t\ttop: 1rem;\n\t\t\t\tz-index: 10;\n\t\t\t\tbackground: rgba(17, 24, 39, 0.95);\n\t\t\t\tbackdrop-filter: blur(10px);\n\t\t\t\tborder: 1px solid rgba(75, 85, 99, 0.3);\n\t\t\t}\n\t\t\t\n\t\t\t/* Progress bar styles */\n\t\t\t.progress-bar {\n\t\t\t\theight: 4px;\n\t\t\t\tbackground: rgba(75, 85, 99, 0.3);\n\t\t\t\tborder-radius: 2px;\n\t\t\t\toverflow: hidden;\n\t\t\t}\n\t\t\t\n\t\t\t.progress-fill {\n\t\t\t\theight: 100%;\n\t\t\t\tbackground: linear-gradient(90deg, #3b82f6, #1d4ed8);\n\t\t\t\ttransition: width 0.2s ease-out;\n\t\t\t\tborder-radius: 2px;\n\t\t\t}\n\t\t\t\n\t\t\t.reading-stats {\n\t\t\t\tfont-size: 0.75rem;\n\t\t\t\tcolor: #9ca3af;\n\t\t\t}\n\t\t\t\n\t\t</style></head><body class=\"bg-gray-900 text-gray-100 min-h-screen\"><div class=\"container mx-auto max-w-4xl px-4 py-8\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><script src=\"https://cdn.tailwindcss.com\"></script><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><!-- Reader styles, shared with the HTML and EPUB exports -->")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templ.Raw("<style>"+export.ReaderCSS+"</style>").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<style>\n\t\t\t/* Keep ToC targets clear of the sticky reader controls */\n\t\t\t.reader-content :is(h1, h2, h3, h4, h5, h6) { scroll-margin-top: 10rem; }\n\t\t\thtml { scroll-behavior: smooth; }\n\t\t\t\n\t\t\t/* Bionic text styles */\n\t\t\t.bionic { font-weight: 600; color: #ffffff; }\n\t\t\t.bionic-word { display: inline; }\n\t\t\t.non-bionic { font-weight: 400; color: #9ca3af; }\n\t\t\t\n\t\t\t/* Reader controls */\n\t\t\t.reader-controls {\n\t\t\t\tposition: sticky;\n\t\t\t\ttop: 1rem;\n\t\t\t\tz-index: 10;\n\t\t\t\tbackground: rgba(17, 24, 39, 0.95);\n\t\t\t\tbackdrop-filter: blur(10px);\n\t\t\t\tborder: 1px solid rgba(75, 85, 99, 0.3);\n\t\t\t}\n\t\t\t\n\t\t\t/* Progress bar styles */\n\t\t\t.progress-bar {\n\t\t\t\theight: 4px;\n\t\t\t\tbackground: rgba(75, 85, 99, 0.3);\n\t\t\t\tborder-radius: 2px;\n\t\t\t\toverflow: hidden;\n\t\t\t}\n\t\t\t\n\t\t\t.progress-fill {\n\t\t\t\theight: 100%;\n\t\t\t\tbackground: linear-gradient(90deg, #3b82f6, #1d4ed8);\n\t\t\t\ttransition: width 0.2s ease-out;\n\t\t\t\tborder-radius: 2px;\n\t\t\t}\n\t\t\t\n\t\t\t.reading-stats {\n\t\t\t\tfont-size: 0.75rem;\n\t\t\t\tcolor: #9ca3af;\n\t\t\t}\n\t\t\t\n\t\t</style></head><body class=\"bg-gray-900 text-gray-100 min-h-screen\"><div class=\"container mx-auto max-w-4xl px-4 py-8\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = UserBar().Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -50,10 +68,91 @@ func Layout(title string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div><!-- Load JavaScript at the end for better performance and availability --><script src=\"/static/js/reader-controls.js\"></script><script src=\"/static/js/reading-progress.js\"></script></body></html>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div><!-- Load JavaScript at the end for better performance and availability --><script src=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		var templ_7745c5c3_Var3 string
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(static.URL("js/reader-controls.js"))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 69, Col: 51}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "\"></script><script src=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var4 string
+		templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(static.URL("js/reading-progress.js"))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 70, Col: 52}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "\"></script></body></html>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+// UserBar shows who is signed in, with a sign-out button for browser sessions
+func UserBar() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var5 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var5 == nil {
+			templ_7745c5c3_Var5 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		if u := auth.FromContext(ctx); u != nil {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"flex items-center justify-end space-x-3 text-sm text-gray-400 mb-4\"><span>Signed in as <span class=\"text-gray-200\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var6 string
+			templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(u.DisplayName())
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/layout.templ`, Line: 78, Col: 67}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "</span></span> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if u.Method == "oidc" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<form method=\"post\" action=\"/logout\"><button type=\"submit\" class=\"bg-gray-700 hover:bg-gray-600 text-gray-200 px-3 py-1 rounded\">Sign out</button></form>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
 		return nil
 	})
 }
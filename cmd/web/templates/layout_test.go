@@ -0,0 +1,20 @@
+package templates
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/export"
+)
+
+// The web reader and the HTML and EPUB exports must share one stylesheet
+func TestLayout_ReaderCSS(t *testing.T) {
+	var b strings.Builder
+	if err := Layout("t").Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), export.ReaderCSS) {
+		t.Error("layout does not include export.ReaderCSS")
+	}
+}
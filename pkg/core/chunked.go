@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Stages of a chunked summary
+const (
+	StageSegment = "segment"
+	StageMerge   = "merge"
+)
+
+// Progress is an event of a chunked summary. Every segment, and then the
+// merge, reports once when it starts and once more with Done set when it
+// finishes.
+type Progress struct {
+	Stage string
+	// Segment numbers the segment from 1; it is 0 for the merge
+	Segment  int
+	Segments int
+	// Start and End bound the segment within the video, or the whole clip
+	// for the merge
+	Start, End time.Duration
+	Done       bool
+	Err        error
+}
+
+// segment is a time range of a video
+type segment struct {
+	start, end time.Duration
+}
+
+// splitSegments cuts a video of the given length into the fewest segments no
+// longer than size, all of about the same length so the last one is not a
+// short remainder. Boundaries fall on whole seconds.
+func splitSegments(length, size time.Duration) []segment {
+	n := int((length + size - 1) / size)
+	if n < 1 {
+		n = 1
+	}
+	segments := make([]segment, n)
+	for i := range segments {
+		segments[i] = segment{
+			start: (length * time.Duration(i) / time.Duration(n)).Truncate(time.Second),
+			end:   (length * time.Duration(i+1) / time.Duration(n)).Truncate(time.Second),
+		}
+	}
+	segments[n-1].end = length
+	return segments
+}
+
+// summarizeChunked summarizes consecutive segments of a video, or of the clip
+// req asks for, concurrently from the video or its transcript, then merges
+// the segment summaries. Clips no longer than one segment are summarized at
+// once.
+func summarizeChunked(ctx context.Context, req Request, input string, languages []string, opts GenerationOptions, chunking config.Chunking) (*generation, error) {
+	var text *transcript.Transcript
+	var length time.Duration
+	if input == config.InputTranscript {
+		t, err := fetchTranscript(ctx, req.URL, languages)
+		if err != nil {
+			return nil, err
+		}
+		text, length = t, t.Duration
+		if n := len(t.Segments); length == 0 && n > 0 {
+			length = t.Segments[n-1].Start + t.Segments[n-1].Duration
+		}
+	} else {
+		v, err := transcripts.Video(ctx, req.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up the video length: %w", err)
+		}
+		length = v.Duration
+	}
+	if length <= 0 {
+		return nil, errors.New("cannot split a video of unknown length, such as a live stream")
+	}
+	end := min(clipEnd(req.End), length)
+	if req.Start >= end {
+		return nil, fmt.Errorf("the clip starts at %s, after the video ends at %s", transcript.Timestamp(req.Start), transcript.Timestamp(length))
+	}
+
+	apiVersion := gemini_api.GetAPIVersion()
+	segments := splitSegments(end-req.Start, time.Duration(chunking.SegmentLength))
+	if len(segments) == 1 {
+		if text != nil {
+			return fromResponse(gemini_api.GenerateTranscriptSummary(ctx, text.Between(req.Start, end).Text(), req.Model, apiVersion, generateConfig(opts)))
+		}
+		return fromResponse(gemini_api.GenerateSummary(ctx, videoOf(req, opts), req.Model, apiVersion, generateConfig(opts)))
+	}
+	for i := range segments {
+		segments[i].start += req.Start
+		segments[i].end += req.Start
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("summarizer.segments", len(segments)))
+	slog.InfoContext(ctx, "Summarizing in segments", "url", req.URL, "length", length, "segments", len(segments), "concurrency", chunking.Concurrency)
+
+	var reportMu sync.Mutex
+	report := func(p Progress) {
+		if req.Progress == nil {
+			return
+		}
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		req.Progress(p)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, chunking.Concurrency)
+		results  = make([]*generation, len(segments))
+	)
+	for i, seg := range segments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			event := Progress{Stage: StageSegment, Segment: i + 1, Segments: len(segments), Start: seg.start, End: seg.end}
+			report(event)
+			var part string
+			if text != nil {
+				// Stretches without speech have nothing to summarize
+				if part = text.Between(seg.start, seg.end).Text(); part == "" {
+					event.Done = true
+					report(event)
+					return
+				}
+			}
+			video := videoOf(req, opts)
+			video.Start, video.End = seg.start, seg.end
+			out, err := fromResponse(gemini_api.GenerateSegmentSummary(ctx, video, part, i+1, len(segments), req.Model, apiVersion, generateConfig(opts)))
+			event.Done, event.Err = true, err
+			report(event)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to summarize segment %d (%s-%s): %w", i+1, transcript.Timestamp(seg.start), transcript.Timestamp(seg.end), err)
+					cancel()
+				}
+				errMu.Unlock()
+				return
+			}
+			results[i] = out
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// Segments skipped because the caller gave up would otherwise be merged
+	// as if they had nothing to summarize
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := &generation{segments: len(segments)}
+	var summaries []string
+	for _, out := range results {
+		if out != nil {
+			summaries = append(summaries, out.text)
+			merged.usage.add(out.usage)
+		}
+	}
+	if len(summaries) == 0 {
+		return nil, errors.New("no segment had anything to summarize")
+	}
+
+	event := Progress{Stage: StageMerge, Segments: len(segments), Start: req.Start, End: end}
+	report(event)
+	out, err := fromResponse(gemini_api.GenerateMergedSummary(ctx, summaries, req.Model, apiVersion, generateConfig(opts)))
+	event.Done, event.Err = true, err
+	report(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge segment summaries: %w", err)
+	}
+	merged.text = out.text
+	merged.usage.add(out.usage)
+	return merged, nil
+}
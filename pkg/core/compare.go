@@ -0,0 +1,34 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Comparison is the outcome of one model in a Compare run
+type Comparison struct {
+	Model  string
+	Result *Result
+	Err    error
+}
+
+// Compare summarizes req.URL with every model concurrently. Results are
+// returned in the order of models; a failing model does not affect the others.
+func Compare(ctx context.Context, req Request, models []string) []Comparison {
+	comparisons := make([]Comparison, len(models))
+
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := req
+			r.Model = model
+			result, err := Summarize(ctx, r)
+			comparisons[i] = Comparison{Model: model, Result: result, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return comparisons
+}
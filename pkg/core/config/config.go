@@ -0,0 +1,645 @@
+// Package config loads Summarizer settings shared by the CLI, web server and
+// MCP server.
+//
+// Settings are resolved in this order, later sources overriding earlier ones:
+//
+//  1. built-in defaults
+//  2. the config file: $SUMMARIZER_CONFIG, or $XDG_CONFIG_HOME/summarizer/config.yaml
+//     (~/.config/summarizer/config.yaml when XDG_CONFIG_HOME is unset)
+//  3. environment variables
+//  4. command line flags
+//
+// Environment variables:
+//
+//	MODEL_NAME                 model
+//	API_VERSION                api_version
+//	SUMMARIZER_CREDENTIALS     credentials.source (env, file or vertex)
+//	SUMMARIZER_API_KEY_FILE    credentials.api_key_file (implies source "file")
+//	GOOGLE_CLOUD_PROJECT       credentials.project
+//	GOOGLE_CLOUD_LOCATION      credentials.location
+//	ALLOWED_MODELS             models.allowed (comma separated)
+//	MODEL_ALIASES              models.aliases ("fast=model,best=model")
+//	MODEL_CATALOG_TTL          cache.model_catalog_ttl
+//	GEMINI_RETRY_ATTEMPTS      retry.attempts
+//	GEMINI_RETRY_BACKOFF       retry.backoff
+//	SUMMARIZER_DB              storage.path
+//	EMBEDDING_MODEL            storage.embedding_model
+//	PORT                       server.addr (as ":PORT")
+//	SERVER_ADDR                server.addr
+//	SHUTDOWN_TIMEOUT           server.shutdown_timeout
+//	SUMMARIZER_API_KEYS        auth.api_keys ("alice=key,bob=key")
+//	OIDC_ISSUER                auth.oidc.issuer
+//	OIDC_CLIENT_ID             auth.oidc.client_id
+//	OIDC_CLIENT_SECRET         auth.oidc.client_secret
+//	OIDC_REDIRECT_URL          auth.oidc.redirect_url
+//	SESSION_SECRET             auth.session_secret
+//	SUMMARIZER_ADMINS          auth.admins (comma separated)
+//	RATE_LIMIT_RPM             limits.requests_per_minute
+//	DAILY_REQUEST_QUOTA        limits.daily_requests
+//	DAILY_TOKEN_QUOTA          limits.daily_tokens
+//	LOG_LEVEL                  log.level (debug, info, warn or error)
+//	LOG_FORMAT                 log.format (text or json)
+//	OTEL_TRACES_EXPORTER       tracing.exporter (otlp, stdout or none)
+//	SUMMARY_INPUT              input.mode (video or transcript)
+//	TRANSCRIPT_LANGUAGES       input.languages (comma separated)
+//	SEGMENT_LENGTH             chunking.segment_length
+//	FACT_CHECK_GROUNDING       fact_check.grounding (true or false)
+//	FACT_CHECK_MAX_CLAIMS      fact_check.max_claims
+//	CHECK_LINKS                links.check (true or false)
+//	BROKEN_LINKS               links.broken (mark or remove)
+//
+// GOOGLE_API_KEY and GEMINI_API_KEY are read directly by the Gemini client
+// when the credentials source is "env". The standard OTEL_EXPORTER_OTLP_*
+// variables are read directly by the OTLP trace exporter.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/logging"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+// Credential sources
+const (
+	CredentialsEnv    = "env"
+	CredentialsFile   = "file"
+	CredentialsVertex = "vertex"
+)
+
+// Input modes
+const (
+	InputVideo      = "video"
+	InputTranscript = "transcript"
+)
+
+// What to do with broken links in a summary
+const (
+	// BrokenLinksMark strikes a broken link through and flags it
+	BrokenLinksMark = "mark"
+	// BrokenLinksRemove keeps the text of a broken link and drops the link
+	BrokenLinksRemove = "remove"
+)
+
+// Config is the complete set of Summarizer settings
+type Config struct {
+	// Model is the default model for summaries
+	Model       string            `yaml:"model"`
+	APIVersion  string            `yaml:"api_version"`
+	Credentials Credentials       `yaml:"credentials"`
+	Generation  Generation        `yaml:"generation,omitempty"`
+	Input       Input             `yaml:"input"`
+	Chunking    Chunking          `yaml:"chunking"`
+	FactCheck   FactCheck         `yaml:"fact_check"`
+	Links       Links             `yaml:"links"`
+	Models      Models            `yaml:"models"`
+	Presets     map[string]Preset `yaml:"presets,omitempty"`
+	Cache       Cache             `yaml:"cache"`
+	Retry       Retry             `yaml:"retry"`
+	Storage     Storage           `yaml:"storage"`
+	Server      Server            `yaml:"server"`
+	Auth        Auth              `yaml:"auth"`
+	Limits      Limits            `yaml:"limits"`
+	Log         Log               `yaml:"log"`
+	Tracing     Tracing           `yaml:"tracing"`
+
+	// Path is the config file that was loaded, if any
+	Path string `yaml:"-"`
+
+	// envErrs are environment variables that could not be parsed, reported by Validate
+	envErrs []error
+}
+
+// Credentials selects how the Gemini client authenticates
+type Credentials struct {
+	// Source is one of "env", "file" or "vertex"
+	Source     string `yaml:"source"`
+	APIKeyFile string `yaml:"api_key_file,omitempty"`
+	Project    string `yaml:"project,omitempty"`
+	Location   string `yaml:"location,omitempty"`
+}
+
+// Input selects what the model is given to summarize
+type Input struct {
+	// Mode is "video" to send the video itself, falling back to its
+	// transcript when Gemini cannot ingest it, or "transcript" to send only
+	// the transcript, which is faster and cheaper
+	Mode string `yaml:"mode"`
+	// Languages are the preferred transcript languages, most preferred first.
+	// Empty uses the video's default caption track.
+	Languages []string `yaml:"languages,omitempty"`
+}
+
+// Chunking tunes map-reduce summaries of long videos, which summarize
+// consecutive segments concurrently and then merge the segment summaries
+type Chunking struct {
+	// SegmentLength is the span of video summarized by each segment
+	SegmentLength Duration `yaml:"segment_length"`
+	// Concurrency bounds the segments summarized at once
+	Concurrency int `yaml:"concurrency"`
+}
+
+// FactCheck tunes fact-checking, which extracts the claims made in a video
+// and verifies each with a separate model call
+type FactCheck struct {
+	// Grounding verifies claims against Google Search results. Models
+	// without search grounding fall back to their own knowledge.
+	Grounding bool `yaml:"grounding"`
+	// MaxClaims bounds the claims checked per video, most confident first
+	MaxClaims int `yaml:"max_claims"`
+	// Concurrency bounds the claims checked at once
+	Concurrency int `yaml:"concurrency"`
+}
+
+// Links tunes the link checker, which requests every link in a summary and
+// marks or removes the broken ones, such as invented "Further Reading" links
+type Links struct {
+	Check bool `yaml:"check"`
+	// Broken is BrokenLinksMark or BrokenLinksRemove
+	Broken string `yaml:"broken"`
+	// Titles replaces bare URLs with links named after the page title
+	Titles bool `yaml:"titles"`
+	// Timeout bounds each link's requests
+	Timeout     Duration `yaml:"timeout"`
+	Concurrency int      `yaml:"concurrency"`
+}
+
+// Models restricts and names the models a deployment offers
+type Models struct {
+	Allowed []string          `yaml:"allowed,omitempty"`
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+}
+
+// Preset is a named bundle of request settings, selected with -preset
+type Preset struct {
+	Model      string     `yaml:"model,omitempty"`
+	Generation Generation `yaml:"generation,omitempty"`
+}
+
+// Cache controls caching of upstream data
+type Cache struct {
+	ModelCatalogTTL Duration `yaml:"model_catalog_ttl"`
+}
+
+// Retry tunes how transient Gemini failures (rate limiting, server and
+// network errors) are retried
+type Retry struct {
+	// Attempts bounds the tries per generation, including the first
+	Attempts int `yaml:"attempts"`
+	// Backoff is the wait before the first retry; the nth retry waits n times as long
+	Backoff Duration `yaml:"backoff"`
+}
+
+// Storage configures the summary store
+type Storage struct {
+	// Path defaults to store.DefaultPath
+	Path           string `yaml:"path"`
+	EmbeddingModel string `yaml:"embedding_model,omitempty"`
+}
+
+// Server configures cmd/web
+type Server struct {
+	Addr string `yaml:"addr"`
+	// ReadTimeout bounds reading a whole request and WriteTimeout producing
+	// the response, which for a summary can take minutes. Zero disables them.
+	ReadTimeout  Duration `yaml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests may run after SIGTERM.
+	// Cloud Run kills the container 10 seconds after SIGTERM.
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+}
+
+// Auth configures who may use cmd/web. With neither API keys nor OIDC
+// configured the server is open to everyone.
+type Auth struct {
+	// APIKeys maps a user name to the key it authenticates with
+	APIKeys map[string]Secret `yaml:"api_keys,omitempty"`
+	OIDC    OIDC              `yaml:"oidc,omitempty"`
+	// SessionSecret signs session cookies; a random secret is generated when
+	// empty, which logs everyone out on restart
+	SessionSecret Secret   `yaml:"session_secret,omitempty"`
+	SessionTTL    Duration `yaml:"session_ttl"`
+	// Admins are the user IDs allowed to see the usage admin page
+	Admins []string `yaml:"admins,omitempty"`
+}
+
+// Enabled reports whether any authentication method is configured
+func (a Auth) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.OIDC.Issuer != ""
+}
+
+// OIDC configures browser login through an OpenID Connect provider
+type OIDC struct {
+	Issuer       string   `yaml:"issuer,omitempty"`
+	ClientID     string   `yaml:"client_id,omitempty"`
+	ClientSecret Secret   `yaml:"client_secret,omitempty"`
+	RedirectURL  string   `yaml:"redirect_url,omitempty"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+}
+
+// Limits throttles cmd/web. Zero values disable a limit.
+type Limits struct {
+	// RequestsPerMinute and Burst size the token bucket of each user, API key
+	// or, for anonymous callers, client IP
+	RequestsPerMinute float64 `yaml:"requests_per_minute"`
+	Burst             int     `yaml:"burst"`
+	// GlobalRequestsPerMinute caps all callers together
+	GlobalRequestsPerMinute float64 `yaml:"global_requests_per_minute"`
+	// DailyRequests and DailyTokens are per-user quotas, reset at midnight UTC
+	DailyRequests int   `yaml:"daily_requests,omitempty"`
+	DailyTokens   int64 `yaml:"daily_tokens,omitempty"`
+	// GlobalDailyTokens caps the tokens spent by the whole deployment per day
+	GlobalDailyTokens int64 `yaml:"global_daily_tokens,omitempty"`
+	// TrustProxy takes the client IP from X-Forwarded-For, as set by a load balancer
+	TrustProxy bool `yaml:"trust_proxy,omitempty"`
+}
+
+// Log configures structured logging
+type Log struct {
+	Level string `yaml:"level"`
+	// Format is "text", or "json" for Cloud Run and other log collectors
+	Format string `yaml:"format"`
+}
+
+// Tracing configures OpenTelemetry tracing
+type Tracing struct {
+	// Exporter is "otlp", "stdout" or "none"
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the OTLP/HTTP endpoint URL, e.g. http://localhost:4318
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// SampleRatio is the fraction of traces started here that are recorded
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Secret is a string that is redacted when the config is shown
+type Secret string
+
+func (s Secret) MarshalText() ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return []byte("REDACTED"), nil
+}
+
+// Duration is a time.Duration written as "1h30m" in config files
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the built-in defaults
+func Default() *Config {
+	return &Config{
+		Model:       gemini_api.ModelName,
+		APIVersion:  gemini_api.APIVersion,
+		Credentials: Credentials{Source: CredentialsEnv},
+		Input:       Input{Mode: InputVideo},
+		Chunking:    Chunking{SegmentLength: Duration(20 * time.Minute), Concurrency: 4},
+		FactCheck:   FactCheck{Grounding: true, MaxClaims: 10, Concurrency: 4},
+		Links:       Links{Broken: BrokenLinksMark, Timeout: Duration(10 * time.Second), Concurrency: 8},
+		Cache:       Cache{ModelCatalogTTL: Duration(time.Hour)},
+		Retry:       Retry{Attempts: gemini_api.RetryAttempts, Backoff: Duration(gemini_api.RetryBackoff)},
+		Storage:     Storage{Path: store.DefaultPath()},
+		Server: Server{
+			Addr:            ":8080",
+			ReadTimeout:     Duration(30 * time.Second),
+			WriteTimeout:    Duration(10 * time.Minute),
+			IdleTimeout:     Duration(2 * time.Minute),
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		Auth:    Auth{SessionTTL: Duration(24 * time.Hour)},
+		Limits:  Limits{RequestsPerMinute: 10, Burst: 5, GlobalRequestsPerMinute: 60},
+		Log:     Log{Level: "info", Format: "text"},
+		Tracing: Tracing{Exporter: tracing.ExporterNone, SampleRatio: 1},
+	}
+}
+
+// DefaultPath returns the config file location
+func DefaultPath() string {
+	if path := os.Getenv("SUMMARIZER_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.yaml"
+	}
+	return filepath.Join(dir, "summarizer", "config.yaml")
+}
+
+// Load resolves defaults, the config file at path and the environment. An
+// empty path uses DefaultPath; a missing file at the default path is not an error.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		cfg.Path = path
+	case errors.Is(err, os.ErrNotExist) && !explicit && os.Getenv("SUMMARIZER_CONFIG") == "":
+		// No config file is fine; defaults and environment apply
+	default:
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg.applyEnv()
+	return cfg, cfg.Validate()
+}
+
+// FromEnv resolves defaults and environment variables only, ignoring any config file
+func FromEnv() *Config {
+	cfg := Default()
+	cfg.applyEnv()
+	return cfg
+}
+
+func (c *Config) applyEnv() {
+	setString := func(dst *string, key string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+
+	record := func(err error) {
+		if err != nil {
+			c.envErrs = append(c.envErrs, err)
+		}
+	}
+
+	setString(&c.Model, "MODEL_NAME")
+	setString(&c.APIVersion, "API_VERSION")
+	setString(&c.Credentials.Source, "SUMMARIZER_CREDENTIALS")
+	if v := os.Getenv("SUMMARIZER_API_KEY_FILE"); v != "" {
+		c.Credentials.Source = CredentialsFile
+		c.Credentials.APIKeyFile = v
+	}
+	setString(&c.Credentials.Project, "GOOGLE_CLOUD_PROJECT")
+	setString(&c.Credentials.Location, "GOOGLE_CLOUD_LOCATION")
+	if v := os.Getenv("ALLOWED_MODELS"); v != "" {
+		c.Models.Allowed = splitList(v)
+	}
+	if v := os.Getenv("MODEL_ALIASES"); v != "" {
+		if c.Models.Aliases == nil {
+			c.Models.Aliases = map[string]string{}
+		}
+		for _, pair := range splitList(v) {
+			if alias, model, ok := strings.Cut(pair, "="); ok {
+				c.Models.Aliases[strings.TrimSpace(alias)] = strings.TrimSpace(model)
+			}
+		}
+	}
+	record(envDuration(&c.Cache.ModelCatalogTTL, "MODEL_CATALOG_TTL"))
+	record(envInt(&c.Retry.Attempts, "GEMINI_RETRY_ATTEMPTS"))
+	record(envDuration(&c.Retry.Backoff, "GEMINI_RETRY_BACKOFF"))
+	setString(&c.Storage.Path, "SUMMARIZER_DB")
+	setString(&c.Storage.EmbeddingModel, "EMBEDDING_MODEL")
+	if v := os.Getenv("PORT"); v != "" {
+		c.Server.Addr = ":" + v
+	}
+	setString(&c.Server.Addr, "SERVER_ADDR")
+	record(envDuration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT"))
+	if v := os.Getenv("SUMMARIZER_API_KEYS"); v != "" {
+		if c.Auth.APIKeys == nil {
+			c.Auth.APIKeys = map[string]Secret{}
+		}
+		for _, pair := range splitList(v) {
+			if user, key, ok := strings.Cut(pair, "="); ok {
+				c.Auth.APIKeys[strings.TrimSpace(user)] = Secret(strings.TrimSpace(key))
+			}
+		}
+	}
+	setString(&c.Auth.OIDC.Issuer, "OIDC_ISSUER")
+	setString(&c.Auth.OIDC.ClientID, "OIDC_CLIENT_ID")
+	setString((*string)(&c.Auth.OIDC.ClientSecret), "OIDC_CLIENT_SECRET")
+	setString(&c.Auth.OIDC.RedirectURL, "OIDC_REDIRECT_URL")
+	setString((*string)(&c.Auth.SessionSecret), "SESSION_SECRET")
+	if v := os.Getenv("SUMMARIZER_ADMINS"); v != "" {
+		c.Auth.Admins = splitList(v)
+	}
+	record(envFloat(&c.Limits.RequestsPerMinute, "RATE_LIMIT_RPM"))
+	record(envInt(&c.Limits.DailyRequests, "DAILY_REQUEST_QUOTA"))
+	record(envInt(&c.Limits.DailyTokens, "DAILY_TOKEN_QUOTA"))
+	setString(&c.Log.Level, "LOG_LEVEL")
+	setString(&c.Log.Format, "LOG_FORMAT")
+	setString(&c.Tracing.Exporter, "OTEL_TRACES_EXPORTER")
+	setString(&c.Input.Mode, "SUMMARY_INPUT")
+	if v := os.Getenv("TRANSCRIPT_LANGUAGES"); v != "" {
+		c.Input.Languages = splitList(v)
+	}
+	record(envDuration(&c.Chunking.SegmentLength, "SEGMENT_LENGTH"))
+	record(envBool(&c.FactCheck.Grounding, "FACT_CHECK_GROUNDING"))
+	record(envInt(&c.FactCheck.MaxClaims, "FACT_CHECK_MAX_CLAIMS"))
+	record(envBool(&c.Links.Check, "CHECK_LINKS"))
+	setString(&c.Links.Broken, "BROKEN_LINKS")
+}
+
+// envDuration sets *dst from the environment variable key, if it is set
+func envDuration(dst *Duration, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	if err := dst.UnmarshalText([]byte(v)); err != nil {
+		return fmt.Errorf("%s must be a duration such as 90s or 20m, got %q", key, v)
+	}
+	return nil
+}
+
+// envBool sets *dst from the environment variable key, if it is set
+func envBool(dst *bool, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false, got %q", key, v)
+	}
+	*dst = parsed
+	return nil
+}
+
+// envInt sets *dst from the environment variable key, if it is set
+func envInt[T int | int64](dst *T, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a whole number, got %q", key, v)
+	}
+	*dst = T(parsed)
+	return nil
+}
+
+// envFloat sets *dst from the environment variable key, if it is set
+func envFloat(dst *float64, key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a number, got %q", key, v)
+	}
+	*dst = parsed
+	return nil
+}
+
+// Validate reports settings that cannot work, including environment
+// variables that could not be parsed
+func (c *Config) Validate() error {
+	errs := slices.Clone(c.envErrs)
+	if c.Model == "" {
+		errs = append(errs, errors.New("model must not be empty"))
+	}
+	switch c.Credentials.Source {
+	case CredentialsEnv:
+	case CredentialsFile:
+		if c.Credentials.APIKeyFile == "" {
+			errs = append(errs, errors.New("credentials.api_key_file is required for source \"file\""))
+		}
+	case CredentialsVertex:
+		if c.Credentials.Project == "" || c.Credentials.Location == "" {
+			errs = append(errs, errors.New("credentials.project and credentials.location are required for source \"vertex\""))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown credentials.source %q", c.Credentials.Source))
+	}
+	if err := c.Generation.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("generation: %w", err))
+	}
+	if c.Input.Mode != InputVideo && c.Input.Mode != InputTranscript {
+		errs = append(errs, fmt.Errorf("input.mode must be video or transcript, got %q", c.Input.Mode))
+	}
+	if c.Chunking.SegmentLength < Duration(time.Minute) || c.Chunking.Concurrency < 1 {
+		errs = append(errs, errors.New("chunking.segment_length must be at least 1m and chunking.concurrency at least 1"))
+	}
+	if c.Retry.Attempts < 1 || c.Retry.Backoff < 0 {
+		errs = append(errs, errors.New("retry.attempts must be at least 1 and retry.backoff not negative"))
+	}
+	if c.FactCheck.MaxClaims < 1 || c.FactCheck.Concurrency < 1 {
+		errs = append(errs, errors.New("fact_check.max_claims and fact_check.concurrency must be at least 1"))
+	}
+	if c.Links.Broken != BrokenLinksMark && c.Links.Broken != BrokenLinksRemove {
+		errs = append(errs, fmt.Errorf("links.broken must be mark or remove, got %q", c.Links.Broken))
+	}
+	if c.Links.Timeout <= 0 || c.Links.Concurrency < 1 {
+		errs = append(errs, errors.New("links.timeout must be positive and links.concurrency at least 1"))
+	}
+	for user, key := range c.Auth.APIKeys {
+		if user == "" || key == "" {
+			errs = append(errs, errors.New("auth.api_keys entries need both a user and a key"))
+			break
+		}
+	}
+	if l := c.Limits; l.RequestsPerMinute < 0 || l.GlobalRequestsPerMinute < 0 || l.Burst < 0 || l.DailyRequests < 0 || l.DailyTokens < 0 || l.GlobalDailyTokens < 0 {
+		errs = append(errs, errors.New("limits must not be negative"))
+	}
+	if s := c.Server; s.ReadTimeout < 0 || s.WriteTimeout < 0 || s.IdleTimeout < 0 || s.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		errs = append(errs, fmt.Errorf("log.format must be text or json, got %q", c.Log.Format))
+	}
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be otlp, stdout or none, got %q", c.Tracing.Exporter))
+	}
+	if r := c.Tracing.SampleRatio; r < 0 || r > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+	if o := c.Auth.OIDC; o.Issuer != "" && (o.ClientID == "" || o.RedirectURL == "") {
+		errs = append(errs, errors.New("auth.oidc.client_id and auth.oidc.redirect_url are required with auth.oidc.issuer"))
+	}
+	for name, preset := range c.Presets {
+		if err := preset.Generation.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("preset %q: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyPreset overlays the named preset onto the config
+func (c *Config) ApplyPreset(name string) error {
+	preset, ok := c.Presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %q", name)
+	}
+	if preset.Model != "" {
+		c.Model = preset.Model
+	}
+	c.Generation = c.Generation.Merge(preset.Generation)
+	return nil
+}
+
+// APIKey reads the API key for the "file" credentials source
+func (c *Config) APIKey() (string, error) {
+	if c.Credentials.Source != CredentialsFile {
+		return "", nil
+	}
+	key, err := os.ReadFile(c.Credentials.APIKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	return strings.TrimSpace(string(key)), nil
+}
+
+// Show writes the effective configuration as YAML
+func Show(w io.Writer, c *Config) error {
+	source := c.Path
+	if source == "" {
+		source = "none (defaults and environment only)"
+	}
+	fmt.Fprintf(w, "# config file: %s\n", source)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads so tests start from defaults
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"MODEL_NAME", "API_VERSION", "SUMMARIZER_CREDENTIALS", "SUMMARIZER_API_KEY_FILE",
+		"GOOGLE_CLOUD_PROJECT", "GOOGLE_CLOUD_LOCATION", "ALLOWED_MODELS", "MODEL_ALIASES",
+		"MODEL_CATALOG_TTL", "SUMMARIZER_DB", "EMBEDDING_MODEL", "PORT", "SERVER_ADDR", "SHUTDOWN_TIMEOUT", "SUMMARIZER_CONFIG",
+		"SUMMARIZER_API_KEYS", "OIDC_ISSUER", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_REDIRECT_URL", "SESSION_SECRET",
+		"SUMMARIZER_ADMINS", "RATE_LIMIT_RPM", "DAILY_REQUEST_QUOTA", "DAILY_TOKEN_QUOTA",
+		"LOG_LEVEL", "LOG_FORMAT", "OTEL_TRACES_EXPORTER", "SUMMARY_INPUT", "TRANSCRIPT_LANGUAGES", "SEGMENT_LENGTH",
+		"FACT_CHECK_GROUNDING", "FACT_CHECK_MAX_CLAIMS", "CHECK_LINKS", "BROKEN_LINKS",
+		"GEMINI_RETRY_ATTEMPTS", "GEMINI_RETRY_BACKOFF",
+	} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const sampleConfig = `
+model: file-model
+api_version: v1
+models:
+  allowed: [file-model, other-model]
+  aliases:
+    fast: other-model
+presets:
+  quick:
+    model: other-model
+cache:
+  model_catalog_ttl: 10m
+server:
+  addr: ":9000"
+`
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Path != "" {
+		t.Errorf("Path = %q, want no config file", cfg.Path)
+	}
+	if cfg.Model != Default().Model || cfg.Server.Addr != ":8080" || cfg.Credentials.Source != CredentialsEnv {
+		t.Errorf("Load() = %+v, want defaults", cfg)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, sampleConfig)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Model != "file-model" || cfg.APIVersion != "v1" || time.Duration(cfg.Cache.ModelCatalogTTL) != 10*time.Minute {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+
+	t.Setenv("MODEL_NAME", "env-model")
+	t.Setenv("PORT", "7000")
+	t.Setenv("SHUTDOWN_TIMEOUT", "8s")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Model != "env-model" || cfg.Server.Addr != ":7000" {
+		t.Errorf("env should override file: model=%q addr=%q", cfg.Model, cfg.Server.Addr)
+	}
+	if cfg.Server.ShutdownTimeout != Duration(8*time.Second) || cfg.Server.WriteTimeout != Default().Server.WriteTimeout {
+		t.Errorf("SHUTDOWN_TIMEOUT not applied: %+v", cfg.Server)
+	}
+	if cfg.APIVersion != "v1" {
+		t.Errorf("unset env should keep file value, got api_version=%q", cfg.APIVersion)
+	}
+
+	t.Setenv("RATE_LIMIT_RPM", "0")
+	t.Setenv("DAILY_TOKEN_QUOTA", "50000")
+	t.Setenv("OTEL_TRACES_EXPORTER", "stdout")
+	t.Setenv("SUMMARY_INPUT", "transcript")
+	t.Setenv("TRANSCRIPT_LANGUAGES", "en, de")
+	t.Setenv("SEGMENT_LENGTH", "15m")
+	t.Setenv("FACT_CHECK_GROUNDING", "false")
+	t.Setenv("FACT_CHECK_MAX_CLAIMS", "3")
+	t.Setenv("CHECK_LINKS", "true")
+	t.Setenv("BROKEN_LINKS", "remove")
+	t.Setenv("GEMINI_RETRY_ATTEMPTS", "5")
+	t.Setenv("GEMINI_RETRY_BACKOFF", "0s")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Limits.RequestsPerMinute != 0 || cfg.Limits.DailyTokens != 50000 || cfg.Limits.Burst != Default().Limits.Burst {
+		t.Errorf("env limits not applied: %+v", cfg.Limits)
+	}
+	if cfg.Tracing.Exporter != "stdout" {
+		t.Errorf("OTEL_TRACES_EXPORTER not applied: %q", cfg.Tracing.Exporter)
+	}
+	if cfg.Input.Mode != InputTranscript || strings.Join(cfg.Input.Languages, ",") != "en,de" {
+		t.Errorf("input env not applied: %+v", cfg.Input)
+	}
+	if cfg.Chunking.SegmentLength != Duration(15*time.Minute) || cfg.Chunking.Concurrency != 4 {
+		t.Errorf("SEGMENT_LENGTH not applied: %+v", cfg.Chunking)
+	}
+	if cfg.FactCheck.Grounding || cfg.FactCheck.MaxClaims != 3 || cfg.FactCheck.Concurrency != 4 {
+		t.Errorf("fact check env not applied: %+v", cfg.FactCheck)
+	}
+	if !cfg.Links.Check || cfg.Links.Broken != BrokenLinksRemove || cfg.Links.Concurrency != 8 {
+		t.Errorf("links env not applied: %+v", cfg.Links)
+	}
+	if cfg.Retry.Attempts != 5 || cfg.Retry.Backoff != 0 {
+		t.Errorf("retry env not applied: %+v", cfg.Retry)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs, true)
+	if err := fs.Parse([]string{"-config", path, "-model", "flag-model", "-addr", ":6000", "-log-format", "json", "-trace-exporter", "otlp", "-input", "video", "-lang", "fr", "-check-links=false"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = flags.Load()
+	if err != nil {
+		t.Fatalf("Flags.Load() error = %v", err)
+	}
+	if cfg.Model != "flag-model" || cfg.Server.Addr != ":6000" || cfg.Log.Format != "json" || cfg.Tracing.Exporter != "otlp" {
+		t.Errorf("flags should override env: model=%q addr=%q", cfg.Model, cfg.Server.Addr)
+	}
+	if cfg.Input.Mode != InputVideo || strings.Join(cfg.Input.Languages, ",") != "fr" {
+		t.Errorf("input flags not applied: %+v", cfg.Input)
+	}
+	if cfg.Links.Check {
+		t.Error("-check-links=false should override CHECK_LINKS")
+	}
+}
+
+func TestFlags_Preset(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, sampleConfig)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs, false)
+	if err := fs.Parse([]string{"-config", path, "-preset", "quick"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Flags.Load() error = %v", err)
+	}
+	if cfg.Model != "other-model" {
+		t.Errorf("Model = %q, want preset model", cfg.Model)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	flags = RegisterFlags(fs, false)
+	fs.Parse([]string{"-config", path, "-preset", "missing"})
+	if _, err := flags.Load(); err == nil {
+		t.Error("unknown preset should fail")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	clearEnv(t)
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("an explicitly named missing file should fail")
+	}
+	if _, err := Load(writeConfig(t, "model: [unterminated")); err == nil {
+		t.Error("invalid YAML should fail")
+	}
+	if _, err := Load(writeConfig(t, "credentials:\n  source: vertex\n")); err == nil {
+		t.Error("vertex credentials without project should fail validation")
+	}
+	if _, err := Load(writeConfig(t, "log:\n  level: loud\n")); err == nil {
+		t.Error("an unknown log level should fail validation")
+	}
+	if _, err := Load(writeConfig(t, "tracing:\n  exporter: zipkin\n")); err == nil {
+		t.Error("an unknown trace exporter should fail validation")
+	}
+	if _, err := Load(writeConfig(t, "fact_check:\n  max_claims: 0\n")); err == nil {
+		t.Error("fact checking no claims should fail validation")
+	}
+	if _, err := Load(writeConfig(t, "retry:\n  attempts: 0\n")); err == nil {
+		t.Error("zero retry attempts should fail validation")
+	}
+	if _, err := Load(writeConfig(t, "links:\n  broken: hide\n")); err == nil {
+		t.Error("an unknown broken links action should fail validation")
+	}
+
+	for key, value := range map[string]string{
+		"MODEL_CATALOG_TTL":     "soon",
+		"SHUTDOWN_TIMEOUT":      "30",
+		"SEGMENT_LENGTH":        "20 minutes",
+		"RATE_LIMIT_RPM":        "lots",
+		"DAILY_REQUEST_QUOTA":   "1e3",
+		"DAILY_TOKEN_QUOTA":     "many",
+		"FACT_CHECK_GROUNDING":  "yes please",
+		"FACT_CHECK_MAX_CLAIMS": "ten",
+		"CHECK_LINKS":           "sure",
+		"GEMINI_RETRY_ATTEMPTS": "3x",
+		"GEMINI_RETRY_BACKOFF":  "2",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := Load(writeConfig(t, ""))
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("Load() error = %v, want one naming %s", err, key)
+			}
+		})
+	}
+}
+
+func TestShow(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load(writeConfig(t, sampleConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Show(&buf, cfg); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"# config file: ", "model: file-model", "model_catalog_ttl: 10m0s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Show() output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGeneration_MergeAndValidate(t *testing.T) {
+	base := Generation{Temperature: ptr(float32(0.2)), MaxOutputTokens: 1000, Safety: map[string]string{"all": "BLOCK_ONLY_HIGH"}}
+	merged := base.Merge(Generation{TopP: ptr(float32(0.9)), Safety: map[string]string{"harassment": "BLOCK_NONE"}})
+
+	if *merged.Temperature != 0.2 || *merged.TopP != 0.9 || merged.MaxOutputTokens != 1000 {
+		t.Errorf("Merge() = %+v", merged)
+	}
+	if merged.Safety["all"] != "BLOCK_ONLY_HIGH" || merged.Safety["harassment"] != "BLOCK_NONE" {
+		t.Errorf("Merge() safety = %v", merged.Safety)
+	}
+	if len(base.Safety) != 1 {
+		t.Error("Merge() must not modify the receiver")
+	}
+	if err := merged.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := []Generation{
+		{Temperature: ptr(float32(3))},
+		{TopP: ptr(float32(1.5))},
+		{TopK: ptr(float32(0))},
+		{ThinkingBudget: ptr(int32(-2))},
+		{Safety: map[string]string{"violence": "BLOCK_NONE"}},
+		{Safety: map[string]string{"all": "BLOCK_SOME"}},
+		{FPS: ptr(0.0)},
+		{FPS: ptr(30.0)},
+		{MediaResolution: "ultra"},
+	}
+	for _, g := range invalid {
+		if err := g.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", g)
+		}
+	}
+}
+
+func TestFlags_Generation(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, "generation:\n  temperature: 0.5\n  seed: 7\n  media_resolution: high\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs, false)
+	if err := fs.Parse([]string{"-config", path, "-temperature", "1.2", "-thinking-budget", "0", "-safety", "hate_speech=block_none", "-fps", "0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Flags.Load() error = %v", err)
+	}
+
+	g := cfg.Generation
+	if *g.Temperature != 1.2 || *g.Seed != 7 || *g.ThinkingBudget != 0 || g.TopP != nil {
+		t.Errorf("Generation = %+v", g)
+	}
+	if g.Safety["hate_speech"] != "BLOCK_NONE" {
+		t.Errorf("Safety = %v", g.Safety)
+	}
+	if g.FPS == nil || *g.FPS != 0.5 || g.MediaResolution != "high" {
+		t.Errorf("FPS = %v, MediaResolution = %q", g.FPS, g.MediaResolution)
+	}
+}
+
+func TestLoad_Auth(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("SUMMARIZER_API_KEYS", "alice=key-a, bob=key-b")
+	t.Setenv("OIDC_ISSUER", "https://idp.example.com")
+
+	if _, err := Load(""); err == nil {
+		t.Error("an OIDC issuer without client settings should fail validation")
+	}
+
+	t.Setenv("OIDC_CLIENT_ID", "summarizer")
+	t.Setenv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/callback")
+	t.Setenv("SESSION_SECRET", "s3cret")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Auth.Enabled() || cfg.Auth.APIKeys["bob"] != "key-b" || cfg.Auth.SessionSecret != "s3cret" {
+		t.Errorf("Auth = %+v", cfg.Auth)
+	}
+
+	var buf bytes.Buffer
+	if err := Show(&buf, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "key-a") || strings.Contains(out, "s3cret") {
+		t.Errorf("Show() leaked a secret:\n%s", out)
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// generationFlags holds the raw values of the generation flags; only flags set
+// on the command line are applied
+type generationFlags struct {
+	temperature     float64
+	topP            float64
+	topK            float64
+	seed            int
+	maxOutputTokens int
+	thinkingBudget  int
+	stop            string
+	safety          string
+	fps             float64
+	mediaResolution string
+}
+
+// Flags are the command line overrides shared by every binary
+type Flags struct {
+	fs *flag.FlagSet
+
+	path        string
+	model       string
+	apiVersion  string
+	preset      string
+	storagePath string
+	input       string
+	languages   string
+	addr        string
+	logLevel    string
+	logFormat   string
+	traces      string
+	checkLinks  bool
+	gen         generationFlags
+}
+
+// RegisterFlags adds the shared configuration flags to fs. withServer also
+// registers -addr for binaries that listen on the network.
+func RegisterFlags(fs *flag.FlagSet, withServer bool) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.path, "config", "", "path to the config file (default "+DefaultPath()+")")
+	fs.StringVar(&f.model, "model", "", "default model")
+	fs.StringVar(&f.apiVersion, "api-version", "", "Gemini API version")
+	fs.StringVar(&f.preset, "preset", "", "named preset from the config file")
+	fs.StringVar(&f.storagePath, "db", "", "summary database path")
+	fs.StringVar(&f.input, "input", "", "what to summarize: video (falls back to the transcript) or transcript")
+	fs.StringVar(&f.languages, "lang", "", "comma separated preferred transcript languages, e.g. en,de")
+	fs.BoolVar(&f.checkLinks, "check-links", false, "check the links in summaries and mark broken ones")
+	if withServer {
+		fs.StringVar(&f.addr, "addr", "", "listen address, e.g. :8080")
+		fs.StringVar(&f.logLevel, "log-level", "", "log level: debug, info, warn or error")
+		fs.StringVar(&f.logFormat, "log-format", "", "log format: text or json")
+		fs.StringVar(&f.traces, "trace-exporter", "", "trace exporter: otlp, stdout or none")
+	}
+
+	fs.Float64Var(&f.gen.temperature, "temperature", 0, "sampling temperature (0-2)")
+	fs.Float64Var(&f.gen.topP, "top-p", 0, "nucleus sampling probability mass (0-1)")
+	fs.Float64Var(&f.gen.topK, "top-k", 0, "sample from the k most likely tokens")
+	fs.IntVar(&f.gen.seed, "seed", 0, "sampling seed for reproducible output")
+	fs.IntVar(&f.gen.maxOutputTokens, "max-output-tokens", 0, "maximum tokens in the summary")
+	fs.IntVar(&f.gen.thinkingBudget, "thinking-budget", 0, "reasoning token budget (0 off, -1 dynamic)")
+	fs.StringVar(&f.gen.stop, "stop", "", "comma separated stop sequences")
+	fs.StringVar(&f.gen.safety, "safety", "", "safety threshold for all categories, or category=THRESHOLD pairs")
+	fs.Float64Var(&f.gen.fps, "fps", 0, "video frames sampled per second (default 1, at most 24)")
+	fs.StringVar(&f.gen.mediaResolution, "media-resolution", "", "detail seen in each video frame: low, medium or high")
+	return f
+}
+
+// Load resolves the configuration with these flags applied on top. Call it
+// after the flag set has been parsed. Only flags given on the command line
+// override other sources.
+func (f *Flags) Load() (*Config, error) {
+	cfg, err := Load(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.preset != "" {
+		if err := cfg.ApplyPreset(f.preset); err != nil {
+			return nil, err
+		}
+	}
+
+	gen := &cfg.Generation
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "temperature":
+			gen.Temperature = ptr(float32(f.gen.temperature))
+		case "top-p":
+			gen.TopP = ptr(float32(f.gen.topP))
+		case "top-k":
+			gen.TopK = ptr(float32(f.gen.topK))
+		case "seed":
+			gen.Seed = ptr(int32(f.gen.seed))
+		case "max-output-tokens":
+			gen.MaxOutputTokens = int32(f.gen.maxOutputTokens)
+		case "thinking-budget":
+			gen.ThinkingBudget = ptr(int32(f.gen.thinkingBudget))
+		case "stop":
+			gen.StopSequences = splitList(f.gen.stop)
+		case "safety":
+			gen.Safety = ParseSafety(f.gen.safety)
+		case "fps":
+			gen.FPS = ptr(f.gen.fps)
+		case "media-resolution":
+			gen.MediaResolution = strings.ToLower(f.gen.mediaResolution)
+		case "model":
+			cfg.Model = f.model
+		case "api-version":
+			cfg.APIVersion = f.apiVersion
+		case "db":
+			cfg.Storage.Path = f.storagePath
+		case "input":
+			cfg.Input.Mode = f.input
+		case "lang":
+			cfg.Input.Languages = splitList(f.languages)
+		case "check-links":
+			cfg.Links.Check = f.checkLinks
+		case "addr":
+			cfg.Server.Addr = f.addr
+		case "log-level":
+			cfg.Log.Level = f.logLevel
+		case "log-format":
+			cfg.Log.Format = f.logFormat
+		case "trace-exporter":
+			cfg.Tracing.Exporter = f.traces
+		}
+	})
+	return cfg, cfg.Validate()
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
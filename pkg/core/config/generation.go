@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SafetyCategories are the harm categories accepted as keys in Generation.Safety.
+// The special key "all" applies a threshold to every category.
+var SafetyCategories = []string{"harassment", "hate_speech", "sexually_explicit", "dangerous_content", "civic_integrity"}
+
+// SafetyThresholds are the accepted values in Generation.Safety
+var SafetyThresholds = []string{"BLOCK_NONE", "BLOCK_ONLY_HIGH", "BLOCK_MEDIUM_AND_ABOVE", "BLOCK_LOW_AND_ABOVE", "OFF"}
+
+// MediaResolutions are the accepted values of Generation.MediaResolution
+var MediaResolutions = []string{"low", "medium", "high"}
+
+// MaxFPS is the highest frame rate Gemini samples videos at
+const MaxFPS = 24
+
+// Generation tunes how the model generates a summary. Nil and zero values
+// leave the model's own defaults in place.
+type Generation struct {
+	Temperature     *float32 `yaml:"temperature,omitempty"`
+	TopP            *float32 `yaml:"top_p,omitempty"`
+	TopK            *float32 `yaml:"top_k,omitempty"`
+	Seed            *int32   `yaml:"seed,omitempty"`
+	MaxOutputTokens int32    `yaml:"max_output_tokens,omitempty"`
+	// ThinkingBudget caps reasoning tokens; 0 disables thinking and -1 lets the model decide
+	ThinkingBudget *int32   `yaml:"thinking_budget,omitempty"`
+	StopSequences  []string `yaml:"stop_sequences,omitempty"`
+	// Safety maps a harm category (or "all") to a block threshold
+	Safety map[string]string `yaml:"safety,omitempty"`
+	// FPS is the rate video frames are sampled at; Gemini defaults to 1.
+	// Lower rates cost fewer tokens, higher ones catch fast action.
+	FPS *float64 `yaml:"fps,omitempty"`
+	// MediaResolution trades the detail seen in each frame for tokens:
+	// low, medium or high
+	MediaResolution string `yaml:"media_resolution,omitempty"`
+}
+
+// Merge returns g with every setting present in override applied on top
+func (g Generation) Merge(override Generation) Generation {
+	merged := g
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.TopK != nil {
+		merged.TopK = override.TopK
+	}
+	if override.Seed != nil {
+		merged.Seed = override.Seed
+	}
+	if override.MaxOutputTokens != 0 {
+		merged.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if override.ThinkingBudget != nil {
+		merged.ThinkingBudget = override.ThinkingBudget
+	}
+	if override.StopSequences != nil {
+		merged.StopSequences = override.StopSequences
+	}
+	if override.FPS != nil {
+		merged.FPS = override.FPS
+	}
+	if override.MediaResolution != "" {
+		merged.MediaResolution = override.MediaResolution
+	}
+	if len(override.Safety) > 0 {
+		merged.Safety = map[string]string{}
+		for k, v := range g.Safety {
+			merged.Safety[k] = v
+		}
+		for k, v := range override.Safety {
+			merged.Safety[k] = v
+		}
+	}
+	return merged
+}
+
+// Validate reports out of range settings
+func (g Generation) Validate() error {
+	var errs []error
+	if g.Temperature != nil && (*g.Temperature < 0 || *g.Temperature > 2) {
+		errs = append(errs, fmt.Errorf("temperature must be between 0 and 2, got %g", *g.Temperature))
+	}
+	if g.TopP != nil && (*g.TopP < 0 || *g.TopP > 1) {
+		errs = append(errs, fmt.Errorf("top_p must be between 0 and 1, got %g", *g.TopP))
+	}
+	if g.TopK != nil && *g.TopK < 1 {
+		errs = append(errs, fmt.Errorf("top_k must be at least 1, got %g", *g.TopK))
+	}
+	if g.MaxOutputTokens < 0 {
+		errs = append(errs, fmt.Errorf("max_output_tokens must be positive, got %d", g.MaxOutputTokens))
+	}
+	if g.ThinkingBudget != nil && *g.ThinkingBudget < -1 {
+		errs = append(errs, fmt.Errorf("thinking_budget must be -1 (dynamic), 0 (off) or positive, got %d", *g.ThinkingBudget))
+	}
+	if len(g.StopSequences) > 5 {
+		errs = append(errs, errors.New("at most 5 stop_sequences are supported"))
+	}
+	if g.FPS != nil && (*g.FPS <= 0 || *g.FPS > MaxFPS) {
+		errs = append(errs, fmt.Errorf("fps must be above 0 and at most %d, got %g", MaxFPS, *g.FPS))
+	}
+	if g.MediaResolution != "" && !slices.Contains(MediaResolutions, g.MediaResolution) {
+		errs = append(errs, fmt.Errorf("unknown media_resolution %q (want one of %s)", g.MediaResolution, strings.Join(MediaResolutions, ", ")))
+	}
+
+	categories := make([]string, 0, len(g.Safety))
+	for category := range g.Safety {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if category != "all" && !slices.Contains(SafetyCategories, category) {
+			errs = append(errs, fmt.Errorf("unknown safety category %q (want all or one of %s)", category, strings.Join(SafetyCategories, ", ")))
+		}
+		if threshold := g.Safety[category]; !slices.Contains(SafetyThresholds, threshold) {
+			errs = append(errs, fmt.Errorf("unknown safety threshold %q (want one of %s)", threshold, strings.Join(SafetyThresholds, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseSafety parses "BLOCK_NONE" (all categories) or
+// "harassment=BLOCK_NONE,hate_speech=BLOCK_ONLY_HIGH"
+func ParseSafety(value string) map[string]string {
+	safety := map[string]string{}
+	for _, item := range splitList(value) {
+		category, threshold, ok := strings.Cut(item, "=")
+		if !ok {
+			category, threshold = "all", item
+		}
+		safety[strings.TrimSpace(category)] = strings.ToUpper(strings.TrimSpace(threshold))
+	}
+	return safety
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+)
+
+var (
+	configMu     sync.RWMutex
+	activeConfig *config.Config
+)
+
+// Configure applies cfg to the package: default model, API version,
+// credentials, caches and storage. Without a call to Configure the package
+// uses defaults and environment variables.
+func Configure(cfg *config.Config) error {
+	apiKey, err := cfg.APIKey()
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Duration(cfg.Retry.Backoff)
+	if backoff == 0 {
+		// Zero selects the default backoff in gemini_api; configured zero means no wait
+		backoff = -1
+	}
+	gemini_api.Configure(gemini_api.Settings{
+		ModelName:     cfg.Model,
+		APIVersion:    cfg.APIVersion,
+		APIKey:        apiKey,
+		Vertex:        cfg.Credentials.Source == config.CredentialsVertex,
+		Project:       cfg.Credentials.Project,
+		Location:      cfg.Credentials.Location,
+		RetryAttempts: cfg.Retry.Attempts,
+		RetryBackoff:  backoff,
+	})
+	DefaultRegistry.SetTTL(time.Duration(cfg.Cache.ModelCatalogTTL))
+
+	configMu.Lock()
+	activeConfig = cfg
+	configMu.Unlock()
+	return nil
+}
+
+// currentConfig returns the configuration passed to Configure, or one built
+// from defaults and the environment
+func currentConfig() *config.Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	if activeConfig != nil {
+		return activeConfig
+	}
+	return config.FromEnv()
+}
@@ -1,14 +1,29 @@
 package core
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
 
-    gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/links"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
 )
 
 const (
-	APIVersion = "v1beta"
-	ModelName  = "gemini-2.5-pro-preview-05-06"
+	APIVersion = gemini_api.APIVersion
+	ModelName  = gemini_api.ModelName
 )
 
 type App struct {
@@ -18,55 +33,9 @@ func NewApp() *App {
 	return &App{}
 }
 
-// func getModelName() string {
-// 	if modelName := os.Getenv("MODEL_NAME"); modelName != "" {
-// 		return modelName
-// 	}
-// 	return ModelName
-// }
-
-// func getAPIVersion() string {
-// 	if apiVersion := os.Getenv("API_VERSION"); apiVersion != "" {
-// 		return apiVersion
-// 	}
-// 	return APIVersion
-// }
-
-// func generateWithYTVideo(url string) (string, error) {
-// 	modelName := getModelName()
-// 	apiVersion := getAPIVersion()
-
-// 	ctx := context.Background()
-// 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-// 		HTTPOptions: genai.HTTPOptions{APIVersion: apiVersion},
-// 	})
-// 	if err != nil {
-// 		return "", fmt.Errorf("failed to create genai client: %w", err)
-// 	}
-
-// 	contents := []*genai.Content{
-// 		{Parts: []*genai.Part{
-// 			{Text: "Write a short summary of the video. Be as information dense as possible. Be thorough. Produce an overall summary, then clearly identify video sections and summarize them."},
-// 			{FileData: &genai.FileData{
-// 				FileURI:  url,
-// 				MIMEType: "video/mp4",
-// 			}},
-// 		}},
-// 	}
-
-// 	resp, err := client.Models.GenerateContent(ctx, modelName, contents, nil)
-// 	if err != nil {
-// 		return "", fmt.Errorf("failed to generate content: %w", err)
-// 	}
-
-// 	respText := resp.Text()
-// 	return respText, nil
-// }
-
 func SummarizeURL(url string) (string, error) {
 	resp, err := gemini_api.GenerateWithYTVideo(url)
 	if err != nil {
-		fmt.Println("Error generating summary:", err)
 		return "", err
 	}
 	return resp, nil
@@ -77,12 +46,318 @@ func SummarizeURLWithModel(url, modelName string) (string, error) {
 	apiVersion := gemini_api.GetAPIVersion()
 	resp, err := gemini_api.GenerateWithYTVideoAndModel(url, modelName, apiVersion)
 	if err != nil {
-		fmt.Println("Error generating summary:", err)
 		return "", err
 	}
 	return resp, nil
 }
 
+// Request describes a single summarization
+type Request struct {
+	URL   string
+	Model string
+	// Input overrides the configured input mode, config.InputVideo or
+	// config.InputTranscript
+	Input string
+	// Languages overrides the preferred transcript languages
+	Languages []string
+	// Start and End clip the video to a time range. A zero End means the end
+	// of the video, and a zero Start uses the t= parameter of URL, if any.
+	Start, End time.Duration
+	// Chunked summarizes consecutive segments of the video concurrently and
+	// merges their summaries, for videos too long to summarize well at once
+	Chunked bool
+	// Progress, if set, receives the progress of a chunked summary, one event
+	// at a time
+	Progress func(Progress)
+	// Generation overrides the configured generation settings
+	Generation GenerationOptions
+}
+
+// Usage reports the tokens consumed by a generation
+type Usage struct {
+	PromptTokens   int32 `json:"prompt_tokens"`
+	OutputTokens   int32 `json:"output_tokens"`
+	ThoughtsTokens int32 `json:"thoughts_tokens"`
+	TotalTokens    int32 `json:"total_tokens"`
+	// VideoTokens and AudioTokens are the parts of PromptTokens spent on the
+	// video's frames and sound track, the cost FPS and MediaResolution tune
+	VideoTokens int32 `json:"video_tokens,omitempty"`
+	AudioTokens int32 `json:"audio_tokens,omitempty"`
+}
+
+// String sums up the tokens, e.g. "1300 tokens (800 video, 200 audio)"
+func (u Usage) String() string {
+	s := fmt.Sprintf("%d tokens", u.TotalTokens)
+	var media []string
+	if u.VideoTokens > 0 {
+		media = append(media, fmt.Sprintf("%d video", u.VideoTokens))
+	}
+	if u.AudioTokens > 0 {
+		media = append(media, fmt.Sprintf("%d audio", u.AudioTokens))
+	}
+	if len(media) > 0 {
+		s += " (" + strings.Join(media, ", ") + ")"
+	}
+	return s
+}
+
+func (u *Usage) add(o Usage) {
+	u.PromptTokens += o.PromptTokens
+	u.OutputTokens += o.OutputTokens
+	u.ThoughtsTokens += o.ThoughtsTokens
+	u.TotalTokens += o.TotalTokens
+	u.VideoTokens += o.VideoTokens
+	u.AudioTokens += o.AudioTokens
+}
+
+// Result is a generated summary together with its cost
+type Result struct {
+	Model string `json:"model"`
+	Text  string `json:"text"`
+	// Input is what was summarized: the video or its transcript
+	Input string `json:"input"`
+	// Fallback is why the transcript was summarized instead of the video
+	Fallback string `json:"fallback,omitempty"`
+	// Segments is the number of segments of a chunked summary
+	Segments int           `json:"segments,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	// Usage adds up every call a summary took
+	Usage Usage `json:"usage"`
+	// Links are the links checked in Text, when link checking is on
+	Links []links.Link `json:"links,omitempty"`
+}
+
+// generation is the text and cost of one or more Gemini calls
+type generation struct {
+	text     string
+	usage    Usage
+	segments int
+}
+
+// fromResponse collects the text and usage of a Gemini response
+func fromResponse(resp *genai.GenerateContentResponse, err error) (*generation, error) {
+	if err != nil {
+		return nil, err
+	}
+	g := &generation{text: resp.Text()}
+	if u := resp.UsageMetadata; u != nil {
+		g.usage = Usage{
+			PromptTokens:   u.PromptTokenCount,
+			OutputTokens:   u.CandidatesTokenCount,
+			ThoughtsTokens: u.ThoughtsTokenCount,
+			TotalTokens:    u.TotalTokenCount,
+		}
+		for _, d := range u.PromptTokensDetails {
+			switch d.Modality {
+			case genai.MediaModalityVideo:
+				g.usage.VideoTokens += d.TokenCount
+			case genai.MediaModalityAudio:
+				g.usage.AudioTokens += d.TokenCount
+			}
+		}
+	}
+	return g, nil
+}
+
+// Summarize generates a Markdown summary for req.URL. An empty req.Model uses
+// the configured default model.
+func Summarize(ctx context.Context, req Request) (result *Result, err error) {
+	if req.Model == "" {
+		req.Model = gemini_api.GetModelName()
+	}
+	ctx, span := tracing.Start(ctx, "core.Summarize",
+		attribute.String("summarizer.url", req.URL),
+		attribute.String("gen_ai.request.model", req.Model),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	j, err := newJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	req = j.Request
+
+	start := time.Now()
+	out, err := j.run(ctx, func(input string) (*generation, error) {
+		switch {
+		case req.Chunked:
+			return summarizeChunked(ctx, req, input, j.languages, j.opts, j.chunking)
+		case input == config.InputTranscript:
+			return summarizeTranscript(ctx, req, j.languages, j.opts)
+		}
+		return fromResponse(gemini_api.GenerateSummary(ctx, videoOf(req, j.opts), req.Model, gemini_api.GetAPIVersion(), generateConfig(j.opts)))
+	})
+	metrics.SummaryDuration.WithLabelValues(req.Model).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.Summaries.WithLabelValues(req.Model, "error").Inc()
+		return nil, err
+	}
+	metrics.Summaries.WithLabelValues(req.Model, "success").Inc()
+
+	var checked []links.Link
+	if j.links.Check {
+		out.text, checked = checkLinks(ctx, out.text, j.links)
+	}
+
+	return &Result{
+		Model:    req.Model,
+		Text:     out.text,
+		Input:    j.input,
+		Fallback: j.fallback,
+		Segments: out.segments,
+		Latency:  time.Since(start),
+		Usage:    out.usage,
+		Links:    checked,
+	}, nil
+}
+
+// job is a validated request with the settings it runs under
+type job struct {
+	Request
+	opts      GenerationOptions
+	input     string
+	languages []string
+	chunking  config.Chunking
+	links     config.Links
+	// fallback is why run fell back to the transcript, if it did
+	fallback string
+}
+
+// newJob applies the configured defaults to req and validates it. req.Model
+// must be set.
+func newJob(ctx context.Context, req Request) (*job, error) {
+	cfg := currentConfig()
+	j := &job{Request: req, opts: cfg.Generation.Merge(req.Generation), chunking: cfg.Chunking, links: cfg.Links}
+	if err := j.opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid generation settings: %w", err)
+	}
+	j.input = cmp.Or(req.Input, cfg.Input.Mode, config.InputVideo)
+	if j.input != config.InputVideo && j.input != config.InputTranscript {
+		return nil, fmt.Errorf("unknown input %q: use %s or %s", j.input, config.InputVideo, config.InputTranscript)
+	}
+	j.languages = req.Languages
+	if len(j.languages) == 0 {
+		j.languages = cfg.Input.Languages
+	}
+	if j.Start == 0 {
+		var err error
+		if j.Start, err = transcript.StartOffset(req.URL); err != nil {
+			return nil, err
+		}
+	}
+	if j.Start < 0 || j.End < 0 || (j.End != 0 && j.End <= j.Start) {
+		return nil, fmt.Errorf("invalid time range %s-%s: the end must come after the start", transcript.Timestamp(j.Start), transcript.Timestamp(j.End))
+	}
+	if j.Start != 0 || j.End != 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("summarizer.start", j.Start.String()), attribute.String("summarizer.end", j.End.String()))
+	}
+	return j, nil
+}
+
+// run calls generate with the job's input and, if a video fails in a way the
+// transcript may not, once more with the transcript. It records the input
+// used and the tokens spent.
+func (j *job) run(ctx context.Context, generate func(input string) (*generation, error)) (*generation, error) {
+	span := trace.SpanFromContext(ctx)
+	out, err := generate(j.input)
+	if err != nil && j.input == config.InputVideo && canFallback(ctx, err) {
+		slog.WarnContext(ctx, "Video generation failed, falling back to the transcript", "url", j.URL, "model", j.Model, "error", err)
+		span.AddEvent("transcript fallback", trace.WithAttributes(attribute.String("error.class", metrics.ErrorClass(err))))
+		videoErr := err
+		out, err = generate(config.InputTranscript)
+		if err != nil {
+			metrics.TranscriptFallbacks.WithLabelValues(j.Model, "error").Inc()
+			err = fmt.Errorf("%w; transcript fallback also failed: %v", videoErr, err)
+		} else {
+			metrics.TranscriptFallbacks.WithLabelValues(j.Model, "success").Inc()
+			j.input = config.InputTranscript
+			j.fallback = videoErr.Error()
+		}
+	}
+	span.SetAttributes(attribute.String("summarizer.input", j.input))
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.Tokens.WithLabelValues(j.Model, "prompt").Add(float64(out.usage.PromptTokens))
+	metrics.Tokens.WithLabelValues(j.Model, "output").Add(float64(out.usage.OutputTokens))
+	metrics.Tokens.WithLabelValues(j.Model, "thoughts").Add(float64(out.usage.ThoughtsTokens))
+	resolution := cmp.Or(j.opts.MediaResolution, "default")
+	metrics.MediaTokens.WithLabelValues(j.Model, "video", resolution).Add(float64(out.usage.VideoTokens))
+	metrics.MediaTokens.WithLabelValues(j.Model, "audio", resolution).Add(float64(out.usage.AudioTokens))
+	return out, nil
+}
+
+// transcripts fetches captions for transcript summaries; tests point it at fixtures
+var transcripts = &transcript.Fetcher{}
+
+// summarizeTranscript summarizes a video, or the clip req asks for, from its captions
+func summarizeTranscript(ctx context.Context, req Request, languages []string, opts GenerationOptions) (*generation, error) {
+	t, err := clipTranscript(ctx, req, languages)
+	if err != nil {
+		return nil, err
+	}
+	return fromResponse(gemini_api.GenerateTranscriptSummary(ctx, t.Text(), req.Model, gemini_api.GetAPIVersion(), generateConfig(opts)))
+}
+
+// clipTranscript fetches the captions of the clip req asks for
+func clipTranscript(ctx context.Context, req Request, languages []string) (*transcript.Transcript, error) {
+	t, err := fetchTranscript(ctx, req.URL, languages)
+	if err != nil {
+		return nil, err
+	}
+	if req.Start != 0 || req.End != 0 {
+		if t = t.Between(req.Start, clipEnd(req.End)); len(t.Segments) == 0 {
+			return nil, fmt.Errorf("failed to fetch transcript: %w between %s and %s", transcript.ErrNoCaptions, transcript.Timestamp(req.Start), transcript.Timestamp(req.End))
+		}
+	}
+	return t, nil
+}
+
+// videoOf is the video req asks to summarize, as sent to Gemini
+func videoOf(req Request, opts GenerationOptions) gemini_api.Video {
+	return gemini_api.Video{URL: req.URL, Start: req.Start, End: req.End, FPS: opts.FPS}
+}
+
+// clipEnd turns the open end of a clip, zero, into the largest offset
+func clipEnd(end time.Duration) time.Duration {
+	if end == 0 {
+		return math.MaxInt64
+	}
+	return end
+}
+
+// fetchTranscript fetches the captions of a video, counting the outcome
+func fetchTranscript(ctx context.Context, url string, languages []string) (*transcript.Transcript, error) {
+	t, err := transcripts.Fetch(ctx, url, languages)
+	switch {
+	case errors.Is(err, transcript.ErrNoCaptions):
+		metrics.Transcripts.WithLabelValues("no_captions").Inc()
+		return nil, fmt.Errorf("failed to fetch transcript: %w", err)
+	case err != nil:
+		metrics.Transcripts.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("failed to fetch transcript: %w", err)
+	}
+	metrics.Transcripts.WithLabelValues("success").Inc()
+	slog.DebugContext(ctx, "Fetched transcript", "url", url, "language", t.Language, "generated", t.Generated, "words", t.Words())
+	return t, nil
+}
+
+// canFallback reports whether a failed video summary is worth retrying from
+// the transcript: Gemini rejects videos it cannot ingest as invalid or fails
+// them server side. Rate limits, bad credentials and unknown models would
+// fail the same way, and a canceled request is not waiting any more.
+func canFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch metrics.ErrorClass(err) {
+	case metrics.ClassInvalidArgument, metrics.ClassServer:
+		return true
+	}
+	return false
+}
+
 // GetModelInfo returns information about the current model being used
 func GetModelInfo() (string, string) {
 	return gemini_api.GetModelName(), gemini_api.GetAPIVersion()
@@ -1,10 +1,17 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"google.golang.org/genai"
 )
 
 func TestNewApp(t *testing.T) {
@@ -97,3 +104,176 @@ func TestSummarizeURL_InvalidURL(t *testing.T) {
 		t.Error("SummarizeURL() should return an error for invalid URL")
 	}
 }
+
+func TestCompare_ReturnsOneResultPerModelInOrder(t *testing.T) {
+	models := []string{"model-a", "model-b", "model-c"}
+	comparisons := Compare(context.Background(), Request{URL: ""}, models)
+
+	if len(comparisons) != len(models) {
+		t.Fatalf("Compare() returned %d results, want %d", len(comparisons), len(models))
+	}
+	for i, c := range comparisons {
+		if c.Model != models[i] {
+			t.Errorf("Compare()[%d].Model = %q, want %q", i, c.Model, models[i])
+		}
+		if c.Err == nil {
+			t.Errorf("Compare()[%d] should fail for an empty URL", i)
+		}
+	}
+}
+
+func TestCanFallback(t *testing.T) {
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"video rejected", ctx, genai.APIError{Code: 400}, true},
+		{"server error", ctx, fmt.Errorf("failed to generate content: %w", genai.APIError{Code: 500}), true},
+		{"rate limited", ctx, genai.APIError{Code: 429}, false},
+		{"bad credentials", ctx, genai.APIError{Code: 403}, false},
+		{"no client", ctx, errors.New("failed to create genai client"), false},
+		{"canceled", canceled, genai.APIError{Code: 500}, false},
+	}
+	for _, tt := range tests {
+		if got := canFallback(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: canFallback() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSummarize_UnknownInput(t *testing.T) {
+	_, err := Summarize(context.Background(), Request{URL: "https://youtu.be/dQw4w9WgXcQ", Model: "m", Input: "audio"})
+	if err == nil || !strings.Contains(err.Error(), `unknown input "audio"`) {
+		t.Errorf("Summarize() error = %v", err)
+	}
+}
+
+func TestSummarize_InvalidRange(t *testing.T) {
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{URL: "https://youtu.be/dQw4w9WgXcQ?t=soon"}, "invalid start in video URL"},
+		{Request{URL: "https://youtu.be/dQw4w9WgXcQ", Start: 70 * time.Minute, End: 40 * time.Minute}, "invalid time range 1:10:00-40:00"},
+		{Request{URL: "https://youtu.be/dQw4w9WgXcQ?t=600", End: 5 * time.Minute}, "invalid time range 10:00-5:00"},
+		{Request{URL: "https://youtu.be/dQw4w9WgXcQ", Start: -time.Second}, "invalid time range"},
+	}
+	for _, tt := range tests {
+		tt.req.Model = "m"
+		_, err := Summarize(context.Background(), tt.req)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Summarize(%+v) error = %v, want %q", tt.req, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateConfig(t *testing.T) {
+	temp, budget := float32(0.3), int32(0)
+	cfg := generateConfig(GenerationOptions{
+		Temperature:     &temp,
+		ThinkingBudget:  &budget,
+		StopSequences:   []string{"END"},
+		Safety:          map[string]string{"all": "BLOCK_ONLY_HIGH", "harassment": "BLOCK_NONE"},
+		MediaResolution: "low",
+	})
+
+	if cfg.Temperature == nil || *cfg.Temperature != 0.3 || cfg.TopP != nil {
+		t.Errorf("sampling settings not mapped: %+v", cfg)
+	}
+	if cfg.MediaResolution != genai.MediaResolutionLow {
+		t.Errorf("MediaResolution = %q, want %q", cfg.MediaResolution, genai.MediaResolutionLow)
+	}
+	if cfg.ThinkingConfig == nil || *cfg.ThinkingConfig.ThinkingBudget != 0 {
+		t.Errorf("ThinkingConfig = %+v, want budget 0", cfg.ThinkingConfig)
+	}
+	if len(cfg.SafetySettings) != 5 {
+		t.Fatalf("got %d safety settings, want one per category", len(cfg.SafetySettings))
+	}
+	for _, s := range cfg.SafetySettings {
+		want := genai.HarmBlockThresholdBlockOnlyHigh
+		if s.Category == genai.HarmCategoryHarassment {
+			want = genai.HarmBlockThresholdBlockNone
+		}
+		if s.Threshold != want {
+			t.Errorf("%s threshold = %s, want %s", s.Category, s.Threshold, want)
+		}
+	}
+
+	if cfg := generateConfig(GenerationOptions{}); cfg.ThinkingConfig != nil || cfg.SafetySettings != nil || cfg.MediaResolution != "" {
+		t.Errorf("empty options should leave model defaults, got %+v", cfg)
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	t.Setenv("GOOGLE_GENAI_USE_VERTEXAI", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	if err := CheckCredentials(); err == nil {
+		t.Error("CheckCredentials() without an API key should fail")
+	}
+
+	t.Setenv("GOOGLE_API_KEY", "test-key")
+	if err := CheckCredentials(); err != nil {
+		t.Errorf("CheckCredentials() with GOOGLE_API_KEY error = %v", err)
+	}
+}
+
+func TestSplitSegments(t *testing.T) {
+	tests := []struct {
+		length, size time.Duration
+		want         []segment
+	}{
+		{10 * time.Minute, 20 * time.Minute, []segment{{0, 10 * time.Minute}}},
+		{40 * time.Minute, 20 * time.Minute, []segment{{0, 20 * time.Minute}, {20 * time.Minute, 40 * time.Minute}}},
+		// 41 minutes is three even segments rather than 20, 20 and 1
+		{41 * time.Minute, 20 * time.Minute, []segment{
+			{0, 13*time.Minute + 40*time.Second},
+			{13*time.Minute + 40*time.Second, 27*time.Minute + 20*time.Second},
+			{27*time.Minute + 20*time.Second, 41 * time.Minute},
+		}},
+		{time.Hour + 500*time.Millisecond, time.Hour, []segment{{0, 30 * time.Minute}, {30 * time.Minute, time.Hour + 500*time.Millisecond}}},
+	}
+	for _, tt := range tests {
+		got := splitSegments(tt.length, tt.size)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("splitSegments(%v, %v) = %v, want %v", tt.length, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestFromResponse_MediaTokens(t *testing.T) {
+	resp := &genai.GenerateContentResponse{UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     1100,
+		CandidatesTokenCount: 200,
+		TotalTokenCount:      1300,
+		PromptTokensDetails: []*genai.ModalityTokenCount{
+			{Modality: genai.MediaModalityText, TokenCount: 100},
+			{Modality: genai.MediaModalityVideo, TokenCount: 800},
+			{Modality: genai.MediaModalityAudio, TokenCount: 200},
+		},
+	}}
+	g, err := fromResponse(resp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{PromptTokens: 1100, OutputTokens: 200, TotalTokens: 1300, VideoTokens: 800, AudioTokens: 200}
+	if g.usage != want {
+		t.Errorf("usage = %+v, want %+v", g.usage, want)
+	}
+	if got := g.usage.String(); got != "1300 tokens (800 video, 200 audio)" {
+		t.Errorf("Usage.String() = %q", got)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	u := Usage{PromptTokens: 1, OutputTokens: 2, ThoughtsTokens: 3, TotalTokens: 6}
+	u.add(Usage{PromptTokens: 10, OutputTokens: 20, ThoughtsTokens: 30, TotalTokens: 60})
+	if want := (Usage{PromptTokens: 11, OutputTokens: 22, ThoughtsTokens: 33, TotalTokens: 66}); u != want {
+		t.Errorf("Usage.add() = %+v, want %+v", u, want)
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+// GeminiEmbedder embeds text with a Gemini embedding model for semantic search
+type GeminiEmbedder struct {
+	Model string
+}
+
+// Name returns the embedding model name
+func (e GeminiEmbedder) Name() string {
+	return e.Model
+}
+
+// Embed returns one vector per text
+func (e GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return gemini_api.EmbedTexts(ctx, texts, e.Model, gemini_api.GetAPIVersion())
+}
+
+// embedderFor returns a Gemini embedder when an embedding model is configured,
+// or nil when semantic search is disabled
+func embedderFor(cfg *config.Config) store.Embedder {
+	if cfg.Storage.EmbeddingModel != "" {
+		return GeminiEmbedder{Model: cfg.Storage.EmbeddingModel}
+	}
+	return nil
+}
+
+// OpenStore opens the configured summary store, enabling semantic search when
+// an embedding model is configured
+func OpenStore() (*store.Store, error) {
+	cfg := currentConfig()
+
+	var opts []store.Option
+	if e := embedderFor(cfg); e != nil {
+		opts = append(opts, store.WithEmbedder(e))
+	}
+	return store.Open(cfg.Storage.Path, opts...)
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+	<rootfiles>
+		<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+	</rootfiles>
+</container>
+`
+
+var epubPackage = template.Must(template.New("opf").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+	<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+		<dc:identifier id="uid">{{.ID}}</dc:identifier>
+		<dc:title>{{.Title}}</dc:title>
+		<dc:language>en</dc:language>
+		<dc:source>{{.URL}}</dc:source>
+		<meta property="dcterms:modified">{{.Modified}}</meta>
+	</metadata>
+	<manifest>
+		<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+		<item id="summary" href="summary.xhtml" media-type="application/xhtml+xml"/>
+		<item id="css" href="reader.css" media-type="text/css"/>
+	</manifest>
+	<spine>
+		<itemref idref="summary"/>
+	</spine>
+</package>
+`))
+
+var epubNav = template.Must(template.New("nav").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>{{.Title}}</title></head>
+<body>
+	<nav epub:type="toc">
+		<ol><li><a href="summary.xhtml">{{.Title}}</a></li></ol>
+	</nav>
+</body>
+</html>
+`))
+
+var epubChapter = template.Must(template.New("chapter").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+	<title>{{.Title}}</title>
+	<link rel="stylesheet" type="text/css" href="reader.css"/>
+</head>
+<body>
+	<div class="reader-content">
+{{.Body}}
+	</div>
+</body>
+</html>
+`))
+
+// writeEPUB writes a single-chapter EPUB 3 book
+func writeEPUB(w io.Writer, summary *store.Summary) error {
+	body, err := MarkdownToHTML(summary.Content)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"ID":       fmt.Sprintf("urn:summarizer:%d:%d", summary.ID, summary.CreatedAt.Unix()),
+		"Title":    summary.Title,
+		"URL":      summary.URL,
+		"Modified": summary.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		"Body":     template.HTML(body),
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"META-INF/container.xml", writeString(epubContainer)},
+		{"OEBPS/content.opf", func(w io.Writer) error { return epubPackage.Execute(w, data) }},
+		{"OEBPS/nav.xhtml", func(w io.Writer) error { return epubNav.Execute(w, data) }},
+		{"OEBPS/summary.xhtml", func(w io.Writer) error { return epubChapter.Execute(w, data) }},
+		{"OEBPS/reader.css", writeString(standaloneCSS)},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if err := f.write(fw); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeString(s string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.Copy(w, strings.NewReader(s))
+		return err
+	}
+}
@@ -0,0 +1,203 @@
+// Package export writes summaries to files in a variety of formats.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+// Format identifies an export format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+	FormatBionic   Format = "bionic"
+	FormatPDF      Format = "pdf"
+	FormatEPUB     Format = "epub"
+	FormatJSON     Format = "json"
+	FormatObsidian Format = "obsidian"
+	FormatLogseq   Format = "logseq"
+)
+
+// Formats lists every supported export format in menu order
+func Formats() []Format {
+	return []Format{FormatMarkdown, FormatHTML, FormatBionic, FormatPDF, FormatEPUB, FormatJSON, FormatObsidian, FormatLogseq}
+}
+
+// ParseFormat accepts a format name or common alias ("markdown", "htm")
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "bionic":
+		return FormatBionic, nil
+	case "pdf":
+		return FormatPDF, nil
+	case "epub":
+		return FormatEPUB, nil
+	case "json":
+		return FormatJSON, nil
+	case "obsidian":
+		return FormatObsidian, nil
+	case "logseq":
+		return FormatLogseq, nil
+	}
+	return "", fmt.Errorf("unknown export format %q", name)
+}
+
+// Label is the human readable name used in menus
+func (f Format) Label() string {
+	switch f {
+	case FormatMarkdown:
+		return "Markdown"
+	case FormatHTML:
+		return "HTML page"
+	case FormatBionic:
+		return "Bionic reading page"
+	case FormatPDF:
+		return "PDF"
+	case FormatEPUB:
+		return "EPUB"
+	case FormatJSON:
+		return "JSON"
+	case FormatObsidian:
+		return "Obsidian note"
+	case FormatLogseq:
+		return "Logseq page"
+	case FormatCSV:
+		return "CSV"
+	}
+	return string(f)
+}
+
+// Extension returns the file extension, including the dot
+func (f Format) Extension() string {
+	switch f {
+	case FormatObsidian, FormatLogseq:
+		return ".md"
+	case FormatBionic:
+		return ".bionic.html"
+	}
+	return "." + string(f)
+}
+
+// ContentType returns the MIME type served for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML, FormatBionic:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatJSON:
+		return "application/json"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Write renders summary in the given format
+func Write(w io.Writer, f Format, summary *store.Summary) error {
+	switch f {
+	case FormatMarkdown:
+		_, err := io.WriteString(w, summary.Content)
+		return err
+	case FormatHTML:
+		return writeHTML(w, summary, render.Options{})
+	case FormatBionic:
+		return writeHTML(w, summary, render.Options{Bionic: true})
+	case FormatPDF:
+		return writePDF(w, summary)
+	case FormatEPUB:
+		return writeEPUB(w, summary)
+	case FormatJSON:
+		return writeJSON(w, summary)
+	case FormatObsidian:
+		return writeNote(w, summary, false)
+	case FormatLogseq:
+		return writeNote(w, summary, true)
+	}
+	return fmt.Errorf("unknown export format %q", f)
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Filename returns a file name derived from the summary title
+func Filename(summary *store.Summary, f Format) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(summary.Title), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.Trim(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "summary"
+	}
+	return slug + f.Extension()
+}
+
+// MarkdownToHTML renders summary Markdown to a sanitized HTML fragment
+func MarkdownToHTML(markdown string) (string, error) {
+	doc, err := render.Render(markdown, render.Options{})
+	if err != nil {
+		return "", err
+	}
+	return doc.HTML, nil
+}
+
+func writeJSON(w io.Writer, summary *store.Summary) error {
+	doc, err := render.Render(summary.Content, render.Options{})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*store.Summary
+		HTML           string           `json:"html"`
+		WordCount      int              `json:"word_count"`
+		ReadingMinutes int              `json:"reading_minutes"`
+		Headings       []render.Heading `json:"headings"`
+	}{summary, doc.HTML, doc.Words, doc.ReadingMinutes, doc.Headings})
+}
+
+// writeNote writes Markdown with YAML front matter for note-taking apps.
+// Logseq reads tags as a comma separated string, Obsidian as a list.
+func writeNote(w io.Writer, summary *store.Summary, logseq bool) error {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(summary.Title))
+	fmt.Fprintf(&b, "source: %s\n", yamlString(summary.URL))
+	fmt.Fprintf(&b, "model: %s\n", yamlString(summary.Model))
+	fmt.Fprintf(&b, "created: %s\n", summary.CreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	if logseq {
+		b.WriteString("tags: summary, video\n")
+	} else {
+		b.WriteString("tags:\n  - summary\n  - video\n")
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(summary.Content)
+	if !strings.HasSuffix(summary.Content, "\n") {
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// yamlString quotes s as a YAML double-quoted scalar
+func yamlString(s string) string {
+	// JSON strings are valid YAML double-quoted scalars
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
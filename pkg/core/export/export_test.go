@@ -0,0 +1,125 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+func testSummary() *store.Summary {
+	return &store.Summary{
+		ID:        7,
+		URL:       "https://www.youtube.com/watch?v=abc",
+		Model:     "gemini-2.0-flash",
+		Title:     `Cats & "Dogs": A Primer`,
+		Content:   "# Cats & \"Dogs\": A Primer\n\nSome **bold** text with a [link](https://example.com).\n\n- one\n- two\n  1. nested\n\n> quoted\n\n```\ncode\n```\n",
+		CreatedAt: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats() {
+		got, err := ParseFormat(string(f))
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if got, _ := ParseFormat("Markdown"); got != FormatMarkdown {
+		t.Errorf("ParseFormat(Markdown) = %q, want md", got)
+	}
+	if _, err := ParseFormat("docx"); err == nil {
+		t.Error("ParseFormat(docx) should fail")
+	}
+}
+
+func TestFilename(t *testing.T) {
+	if got := Filename(testSummary(), FormatObsidian); got != "cats-dogs-a-primer.md" {
+		t.Errorf("Filename() = %q", got)
+	}
+	if got := Filename(testSummary(), FormatBionic); got != "cats-dogs-a-primer.bionic.html" {
+		t.Errorf("Filename() for bionic = %q", got)
+	}
+	if got := Filename(&store.Summary{}, FormatPDF); got != "summary.pdf" {
+		t.Errorf("Filename() for untitled summary = %q", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format Format
+		check  func(t *testing.T, out []byte)
+	}{
+		{FormatMarkdown, func(t *testing.T, out []byte) {
+			if string(out) != testSummary().Content {
+				t.Errorf("markdown export altered content: %q", out)
+			}
+		}},
+		{FormatHTML, func(t *testing.T, out []byte) {
+			for _, want := range []string{"<!DOCTYPE html>", ".reader-content", "<strong>bold</strong>", "Cats &amp; &#34;Dogs&#34;", "16 words · ~1 min read"} {
+				if !bytes.Contains(out, []byte(want)) {
+					t.Errorf("html export missing %q", want)
+				}
+			}
+			if !bytes.Contains(out, []byte(ReaderCSS)) {
+				t.Error("html export is not styled with the shared reader stylesheet")
+			}
+		}},
+		{FormatBionic, func(t *testing.T, out []byte) {
+			for _, want := range []string{"<!DOCTYPE html>", "<strong><b>bo</b>ld</strong>", "<pre><code>code\n"} {
+				if !bytes.Contains(out, []byte(want)) {
+					t.Errorf("bionic export missing %q", want)
+				}
+			}
+		}},
+		{FormatPDF, func(t *testing.T, out []byte) {
+			if !bytes.HasPrefix(out, []byte("%PDF-")) {
+				t.Errorf("pdf export has no PDF header")
+			}
+		}},
+		{FormatEPUB, func(t *testing.T, out []byte) {
+			zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+			if err != nil {
+				t.Fatalf("epub is not a zip: %v", err)
+			}
+			if zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+				t.Errorf("epub must start with an uncompressed mimetype entry")
+			}
+		}},
+		{FormatJSON, func(t *testing.T, out []byte) {
+			var doc map[string]any
+			if err := json.Unmarshal(out, &doc); err != nil {
+				t.Fatalf("invalid json: %v", err)
+			}
+			if doc["url"] != testSummary().URL || !strings.Contains(doc["html"].(string), "<ul>") ||
+				doc["word_count"] != 16.0 || doc["reading_minutes"] != 1.0 {
+				t.Errorf("json export = %v", doc)
+			}
+		}},
+		{FormatObsidian, func(t *testing.T, out []byte) {
+			want := "---\ntitle: \"Cats \\u0026 \\\"Dogs\\\": A Primer\"\n"
+			if !strings.HasPrefix(string(out), want) || !strings.Contains(string(out), "tags:\n  - summary\n") {
+				t.Errorf("obsidian front matter = %q", out)
+			}
+		}},
+		{FormatLogseq, func(t *testing.T, out []byte) {
+			if !strings.Contains(string(out), "tags: summary, video\n---\n\n# Cats") {
+				t.Errorf("logseq note = %q", out)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, testSummary()); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
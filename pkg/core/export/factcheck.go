@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+)
+
+// FactCheckFormats lists the formats a fact check can be written in
+func FactCheckFormats() []Format {
+	return []Format{FormatMarkdown, FormatJSON}
+}
+
+// FactCheckFilename names a fact check file after the video, e.g. "factcheck-dQw4w9WgXcQ.md"
+func FactCheckFilename(r *core.FactCheckResult, f Format) string {
+	name := "factcheck"
+	if id, err := transcript.VideoID(r.URL); err == nil {
+		name += "-" + id
+	}
+	return name + f.Extension()
+}
+
+// WriteFactCheck writes the verdicts of a fact check as an annotated Markdown
+// section or as JSON
+func WriteFactCheck(w io.Writer, f Format, r *core.FactCheckResult) error {
+	switch f {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatMarkdown:
+		_, err := io.WriteString(w, FactCheckMarkdown(r))
+		return err
+	}
+	return fmt.Errorf("unknown fact check format %q (want md or json)", f)
+}
+
+// FactCheckMarkdown renders a fact check as a "Fact Check" section: a tally
+// of the verdicts, then each claim with its verdict, explanation and sources
+func FactCheckMarkdown(r *core.FactCheckResult) string {
+	var b strings.Builder
+	b.WriteString("## Fact Check\n\n")
+	if len(r.Checks) == 0 {
+		b.WriteString("No factual claims were found.\n")
+		return b.String()
+	}
+
+	var tally []string
+	for _, verdict := range core.Verdicts {
+		if n := r.Count(verdict); n > 0 {
+			tally = append(tally, fmt.Sprintf("%d %s", n, verdict))
+		}
+	}
+	fmt.Fprintf(&b, "%d of %d claims checked: %s.\n", len(r.Checks), r.Claims, strings.Join(tally, ", "))
+
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "\n### [%s] %s\n\n", transcript.Timestamp(c.Claim.Timestamp), c.Claim.Text)
+		b.WriteString("**" + VerdictLabel(c.Verdict) + "**")
+		if c.Claim.Speaker != "" {
+			b.WriteString(" · " + c.Claim.Speaker)
+		}
+		if !c.Grounded && c.Error == "" {
+			b.WriteString(" · *not checked against search results*")
+		}
+		b.WriteString("\n")
+		if c.Error != "" {
+			fmt.Fprintf(&b, "\nCould not be checked: %s\n", c.Error)
+		}
+		if c.Explanation != "" {
+			b.WriteString("\n" + c.Explanation + "\n")
+		}
+		if len(c.Citations) > 0 {
+			b.WriteString("\nSources:\n\n")
+			for _, cite := range c.Citations {
+				fmt.Fprintf(&b, "- [%s](%s)\n", strings.ReplaceAll(cite.Title, "]", `\]`), cite.URL)
+			}
+		}
+	}
+	return b.String()
+}
+
+// VerdictLabel is the human readable name of a verdict
+func VerdictLabel(verdict string) string {
+	switch verdict {
+	case core.VerdictSupported:
+		return "Supported"
+	case core.VerdictRefuted:
+		return "Refuted"
+	case core.VerdictMixed:
+		return "Mixed"
+	}
+	return "Unverified"
+}
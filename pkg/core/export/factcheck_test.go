@@ -0,0 +1,78 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+)
+
+func testFactCheck() *core.FactCheckResult {
+	return &core.FactCheckResult{
+		URL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Model:  "gemini-2.5-flash",
+		Claims: 3,
+		Checks: []core.Check{
+			{
+				Claim: core.Statement{Kind: core.KindClaim, Text: "The song was released in 1987.", Speaker: "Rick", Timestamp: 43 * time.Second},
+				Verification: core.Verification{
+					Verdict:     core.VerdictSupported,
+					Explanation: "It was released in July 1987.",
+					Citations:   []core.Citation{{Title: "wikipedia.org", URL: "https://example.com/grounding/1"}},
+					Grounded:    true,
+				},
+			},
+			{
+				Claim:        core.Statement{Kind: core.KindClaim, Text: "It topped the charts in 40 countries.", Timestamp: time.Hour + 2*time.Minute},
+				Verification: core.Verification{Verdict: core.VerdictUnverified},
+				Error:        "rate limited",
+			},
+		},
+	}
+}
+
+func TestWriteFactCheck(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFactCheck(&buf, FormatMarkdown, testFactCheck()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Fact Check",
+		"2 of 3 claims checked: 1 supported, 1 unverified.",
+		"### [0:43] The song was released in 1987.\n\n**Supported** · Rick\n\nIt was released in July 1987.",
+		"- [wikipedia.org](https://example.com/grounding/1)",
+		"### [1:02:00] It topped the charts in 40 countries.\n\n**Unverified**\n\nCould not be checked: rate limited",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := WriteFactCheck(&buf, FormatJSON, testFactCheck()); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Checks []struct {
+			Claim   struct{ Text string } `json:"claim"`
+			Verdict string                `json:"verdict"`
+			Error   string                `json:"error"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Checks) != 2 || doc.Checks[0].Verdict != core.VerdictSupported || doc.Checks[1].Error != "rate limited" || doc.Checks[0].Claim.Text == "" {
+		t.Errorf("json export = %s", buf.String())
+	}
+
+	if got := FactCheckFilename(testFactCheck(), FormatMarkdown); got != "factcheck-dQw4w9WgXcQ.md" {
+		t.Errorf("FactCheckFilename() = %q", got)
+	}
+	if err := WriteFactCheck(&buf, FormatCSV, testFactCheck()); err == nil {
+		t.Error("WriteFactCheck(csv) should fail")
+	}
+}
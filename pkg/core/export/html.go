@@ -0,0 +1,63 @@
+package export
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/render"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+)
+
+// ReaderCSS styles rendered summaries inside a .reader-content element. The
+// web reader links the same stylesheet, so exports look like the page they
+// were downloaded from.
+//
+//go:embed reader.css
+var ReaderCSS string
+
+// pageCSS styles the rest of a standalone HTML or EPUB page
+//
+//go:embed page.css
+var pageCSS string
+
+// standaloneCSS is the stylesheet of exported HTML and EPUB files
+var standaloneCSS = pageCSS + "\n" + ReaderCSS
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8"/>
+	<meta name="viewport" content="width=device-width, initial-scale=1.0"/>
+	<title>{{.Title}}</title>
+	<style>{{.CSS}}</style>
+</head>
+<body>
+	<div class="reader-meta">
+		<a href="{{.URL}}">{{.URL}}</a> · {{.Model}} · {{.Created}} · {{.Words}} words · ~{{.Minutes}} min read
+	</div>
+	<article class="reader-content">
+{{.Body}}
+	</article>
+</body>
+</html>
+`))
+
+// writeHTML writes a self-contained page styled like the web reader
+func writeHTML(w io.Writer, summary *store.Summary, opts render.Options) error {
+	doc, err := render.Render(summary.Content, opts)
+	if err != nil {
+		return err
+	}
+
+	return pageTemplate.Execute(w, map[string]any{
+		"Title":   summary.Title,
+		"URL":     summary.URL,
+		"Model":   summary.Model,
+		"Created": summary.CreatedAt.Format("2006-01-02"),
+		"CSS":     template.CSS(standaloneCSS),
+		"Words":   doc.Words,
+		"Minutes": doc.ReadingMinutes,
+		"Body":    template.HTML(doc.HTML),
+	})
+}
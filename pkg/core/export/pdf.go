@@ -0,0 +1,203 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/store"
+	"github.com/go-pdf/fpdf"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	pdfFont       = "Times"
+	pdfMonoFont   = "Courier"
+	pdfBodySize   = 11.0
+	pdfLineHeight = 5.5
+	pdfIndent     = 6.0
+)
+
+var pdfHeadingSizes = map[int]float64{1: 20, 2: 16, 3: 13}
+
+// pdfWriter lays out a goldmark AST with the fpdf core fonts
+type pdfWriter struct {
+	pdf    *fpdf.Fpdf
+	src    []byte
+	tr     func(string) string
+	margin float64
+	size   float64
+}
+
+// writePDF renders the summary Markdown as a simple typeset A4 document
+func writePDF(w io.Writer, summary *store.Summary) error {
+	src := []byte(summary.Content)
+	doc := goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser().Parse(text.NewReader(src))
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(summary.Title, true)
+	pdf.SetSubject(summary.URL, true)
+	pdf.SetAuthor(summary.Model, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AddPage()
+
+	left, _, _, _ := pdf.GetMargins()
+	p := &pdfWriter{pdf: pdf, src: src, tr: pdf.UnicodeTranslatorFromDescriptor(""), margin: left, size: pdfBodySize}
+
+	pdf.SetFont(pdfFont, "I", 9)
+	pdf.SetTextColor(110, 110, 110)
+	pdf.MultiCell(0, 4.5, p.tr(fmt.Sprintf("%s · %s · %s", summary.URL, summary.Model, summary.CreatedAt.Format("2006-01-02"))), "", "L", false)
+	pdf.Ln(4)
+	pdf.SetTextColor(0, 0, 0)
+
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		p.block(n, 0)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render pdf: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func (p *pdfWriter) block(n ast.Node, depth int) {
+	p.pdf.SetLeftMargin(p.margin + float64(depth)*pdfIndent)
+	p.pdf.SetX(p.margin + float64(depth)*pdfIndent)
+
+	switch n := n.(type) {
+	case *ast.Heading:
+		size, ok := pdfHeadingSizes[n.Level]
+		if !ok {
+			size = pdfBodySize + 1
+		}
+		p.pdf.Ln(2)
+		p.inlines(n, "B", size)
+		p.pdf.Ln(size * 0.6)
+
+	case *ast.Paragraph, *ast.TextBlock:
+		p.inlines(n, "", pdfBodySize)
+		p.pdf.Ln(pdfLineHeight)
+		if n.Kind() == ast.KindParagraph {
+			p.pdf.Ln(2)
+		}
+
+	case *ast.List:
+		number := n.Start
+		for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+			marker := "•"
+			if n.IsOrdered() {
+				marker = fmt.Sprintf("%d.", number)
+				number++
+			}
+			p.pdf.SetFont(pdfFont, "", pdfBodySize)
+			p.pdf.SetX(p.margin + float64(depth)*pdfIndent)
+			p.pdf.Write(pdfLineHeight, p.tr(marker+" "))
+			for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+				if child == item.FirstChild() && child.Kind() != ast.KindList {
+					p.pdf.SetLeftMargin(p.margin + float64(depth+1)*pdfIndent)
+					p.inlines(child, "", pdfBodySize)
+					p.pdf.Ln(pdfLineHeight)
+					continue
+				}
+				p.block(child, depth+1)
+			}
+		}
+		p.pdf.Ln(2)
+
+	case *ast.Blockquote:
+		p.pdf.SetTextColor(90, 90, 90)
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			p.block(child, depth+1)
+		}
+		p.pdf.SetTextColor(0, 0, 0)
+
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		var b strings.Builder
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			b.Write(seg.Value(p.src))
+		}
+		p.pdf.SetFont(pdfMonoFont, "", 9)
+		p.pdf.MultiCell(0, 4.5, p.tr(strings.TrimRight(b.String(), "\n")), "", "L", false)
+		p.pdf.Ln(2)
+
+	case *ast.ThematicBreak:
+		y := p.pdf.GetY() + 2
+		p.pdf.Line(p.margin, y, 190, y)
+		p.pdf.Ln(6)
+
+	default:
+		// Tables, HTML blocks and other extensions fall back to their text
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			p.block(child, depth)
+		}
+	}
+}
+
+// inlines writes the inline children of n, wrapping within the current margins
+func (p *pdfWriter) inlines(n ast.Node, style string, size float64) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		p.inline(child, style, size)
+	}
+}
+
+func (p *pdfWriter) inline(n ast.Node, style string, size float64) {
+	p.pdf.SetFont(pdfFont, style, size)
+	height := size * 0.5
+
+	switch n := n.(type) {
+	case *ast.Text:
+		p.pdf.Write(height, p.tr(string(n.Segment.Value(p.src))))
+		if n.HardLineBreak() {
+			p.pdf.Ln(height)
+		} else if n.SoftLineBreak() {
+			p.pdf.Write(height, " ")
+		}
+	case *ast.String:
+		p.pdf.Write(height, p.tr(string(n.Value)))
+	case *ast.CodeSpan:
+		p.pdf.SetFont(pdfMonoFont, "", size-1)
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			if t, ok := child.(*ast.Text); ok {
+				p.pdf.Write(height, p.tr(string(t.Segment.Value(p.src))))
+			}
+		}
+	case *ast.Emphasis:
+		next := style
+		if n.Level >= 2 {
+			next += "B"
+		} else {
+			next += "I"
+		}
+		p.inlines(n, next, size)
+	case *ast.Link:
+		p.pdf.SetTextColor(30, 80, 200)
+		p.pdf.SetFont(pdfFont, style+"U", size)
+		p.pdf.WriteLinkString(height, p.tr(plainText(n, p.src)), string(n.Destination))
+		p.pdf.SetTextColor(0, 0, 0)
+	case *ast.AutoLink:
+		url := string(n.URL(p.src))
+		p.pdf.SetTextColor(30, 80, 200)
+		p.pdf.WriteLinkString(height, p.tr(url), url)
+		p.pdf.SetTextColor(0, 0, 0)
+	default:
+		p.inlines(n, style, size)
+	}
+}
+
+// plainText concatenates the text of all descendants of n
+func plainText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := c.(*ast.Text); ok && entering {
+			b.Write(t.Segment.Value(src))
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+)
+
+// FormatCSV writes extracted statements as comma separated values. It only
+// applies to statements, not summaries.
+const FormatCSV Format = "csv"
+
+// StatementFormats lists the formats extracted statements can be written in
+func StatementFormats() []Format {
+	return []Format{FormatCSV, FormatJSON, FormatMarkdown}
+}
+
+// ParseStatementFormat accepts a statement format name or alias ("markdown")
+func ParseStatementFormat(name string) (Format, error) {
+	if strings.EqualFold(strings.TrimSpace(name), string(FormatCSV)) {
+		return FormatCSV, nil
+	}
+	f, err := ParseFormat(name)
+	if err != nil || (f != FormatJSON && f != FormatMarkdown) {
+		return "", fmt.Errorf("unknown statement format %q (want csv, json or md)", name)
+	}
+	return f, nil
+}
+
+// StatementsFilename names a statements file after the video, e.g. "statements-dQw4w9WgXcQ.csv"
+func StatementsFilename(e *core.Extraction, f Format) string {
+	name := "statements"
+	if id, err := transcript.VideoID(e.URL); err == nil {
+		name += "-" + id
+	}
+	return name + f.Extension()
+}
+
+// WriteStatements writes extracted quotes and claims as CSV, JSON or a
+// Markdown table. Timestamps are written as m:ss and in seconds.
+func WriteStatements(w io.Writer, f Format, e *core.Extraction) error {
+	switch f {
+	case FormatCSV:
+		return writeStatementsCSV(w, e)
+	case FormatJSON:
+		return writeStatementsJSON(w, e)
+	case FormatMarkdown:
+		return writeStatementsTable(w, e)
+	}
+	return fmt.Errorf("unknown statement format %q", f)
+}
+
+func writeStatementsCSV(w io.Writer, e *core.Extraction) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"timestamp", "seconds", "kind", "speaker", "text", "confidence"})
+	for _, s := range e.Statements {
+		cw.Write([]string{
+			transcript.Timestamp(s.Timestamp),
+			strconv.Itoa(int(s.Timestamp.Seconds())),
+			s.Kind,
+			s.Speaker,
+			s.Text,
+			strconv.FormatFloat(s.Confidence, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeStatementsJSON(w io.Writer, e *core.Extraction) error {
+	type statement struct {
+		Timestamp  string  `json:"timestamp"`
+		Seconds    int     `json:"seconds"`
+		Kind       string  `json:"kind"`
+		Speaker    string  `json:"speaker,omitempty"`
+		Text       string  `json:"text"`
+		Confidence float64 `json:"confidence"`
+	}
+	statements := make([]statement, len(e.Statements))
+	for i, s := range e.Statements {
+		statements[i] = statement{transcript.Timestamp(s.Timestamp), int(s.Timestamp.Seconds()), s.Kind, s.Speaker, s.Text, s.Confidence}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		URL        string      `json:"url"`
+		Model      string      `json:"model"`
+		Input      string      `json:"input"`
+		Statements []statement `json:"statements"`
+	}{e.URL, e.Model, e.Input, statements})
+}
+
+func writeStatementsTable(w io.Writer, e *core.Extraction) error {
+	var b strings.Builder
+	b.WriteString("| Time | Kind | Speaker | Statement | Confidence |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, s := range e.Statements {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %.0f%% |\n",
+			transcript.Timestamp(s.Timestamp), s.Kind, tableCell(s.Speaker), tableCell(s.Text), s.Confidence*100)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// tableCell escapes text for a Markdown table cell
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core"
+)
+
+func testExtraction() *core.Extraction {
+	return &core.Extraction{
+		URL:   "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Model: "gemini-2.0-flash",
+		Input: "video",
+		Statements: []core.Statement{
+			{Kind: core.KindQuote, Text: "Never gonna give you up, | never", Speaker: "Rick", Timestamp: 43 * time.Second, Confidence: 0.95},
+			{Kind: core.KindClaim, Text: "The song was released in 1987,\nin the UK.", Timestamp: time.Hour + 2*time.Minute, Confidence: 0.5},
+		},
+	}
+}
+
+func TestParseStatementFormat(t *testing.T) {
+	for _, f := range StatementFormats() {
+		if got, err := ParseStatementFormat(string(f)); err != nil || got != f {
+			t.Errorf("ParseStatementFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	for _, name := range []string{"pdf", "docx"} {
+		if _, err := ParseStatementFormat(name); err == nil {
+			t.Errorf("ParseStatementFormat(%q) should fail", name)
+		}
+	}
+	if got := StatementsFilename(testExtraction(), FormatCSV); got != "statements-dQw4w9WgXcQ.csv" {
+		t.Errorf("StatementsFilename() = %q", got)
+	}
+}
+
+func TestWriteStatements(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteStatements(&buf, FormatCSV, testExtraction()); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"timestamp", "seconds", "kind", "speaker", "text", "confidence"},
+		{"0:43", "43", "quote", "Rick", "Never gonna give you up, | never", "0.95"},
+		{"1:02:00", "3720", "claim", "", "The song was released in 1987,\nin the UK.", "0.50"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("csv has %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], "\x00") != strings.Join(want[i], "\x00") {
+			t.Errorf("csv record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	buf.Reset()
+	if err := WriteStatements(&buf, FormatJSON, testExtraction()); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		URL        string `json:"url"`
+		Statements []struct {
+			Timestamp string `json:"timestamp"`
+			Seconds   int    `json:"seconds"`
+			Speaker   string `json:"speaker"`
+		} `json:"statements"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.URL == "" || len(doc.Statements) != 2 || doc.Statements[1].Timestamp != "1:02:00" || doc.Statements[1].Seconds != 3720 || doc.Statements[0].Speaker != "Rick" {
+		t.Errorf("json export = %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteStatements(&buf, FormatMarkdown, testExtraction()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| 0:43 | quote | Rick | Never gonna give you up, \\| never | 95% |",
+		"| 1:02:00 | claim |  | The song was released in 1987, in the UK. | 50% |",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown table missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if err := WriteStatements(&buf, FormatPDF, testExtraction()); err == nil {
+		t.Error("WriteStatements(pdf) should fail")
+	}
+}
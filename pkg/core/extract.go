@@ -0,0 +1,162 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Kinds of extracted statements
+const (
+	KindQuote = "quote"
+	KindClaim = "claim"
+)
+
+// Statement is a quote or claim made in a video
+type Statement struct {
+	Kind string `json:"kind"`
+	// Text is verbatim for quotes and a one sentence paraphrase for claims
+	Text string `json:"text"`
+	// Speaker is empty when the video does not say who is speaking
+	Speaker string `json:"speaker,omitempty"`
+	// Timestamp is where the statement is made in the video
+	Timestamp time.Duration `json:"timestamp"`
+	// Confidence is the model's 0-1 confidence that a quote is verbatim or
+	// that a claim is really made
+	Confidence float64 `json:"confidence"`
+}
+
+// Extraction is the quotes and claims of a video together with their cost
+type Extraction struct {
+	URL        string      `json:"url"`
+	Model      string      `json:"model"`
+	Statements []Statement `json:"statements"`
+	// Input is what was read: the video or its transcript
+	Input string `json:"input"`
+	// Fallback is why the transcript was read instead of the video
+	Fallback string        `json:"fallback,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Usage    Usage         `json:"usage"`
+}
+
+// Quotes returns the statements of kind KindQuote
+func (e *Extraction) Quotes() []Statement {
+	return e.ofKind(KindQuote)
+}
+
+// Claims returns the statements of kind KindClaim
+func (e *Extraction) Claims() []Statement {
+	return e.ofKind(KindClaim)
+}
+
+func (e *Extraction) ofKind(kind string) []Statement {
+	var statements []Statement
+	for _, s := range e.Statements {
+		if s.Kind == kind {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+// Extract lists the verbatim quotes and factual claims made in req.URL, in
+// video order. It honours the same input, clip and generation settings as
+// Summarize, but reads the whole video or clip at once: req.Chunked and
+// req.Progress are ignored.
+func Extract(ctx context.Context, req Request) (result *Extraction, err error) {
+	if req.Model == "" {
+		req.Model = gemini_api.GetModelName()
+	}
+	ctx, span := tracing.Start(ctx, "core.Extract",
+		attribute.String("summarizer.url", req.URL),
+		attribute.String("gen_ai.request.model", req.Model),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	j, err := newJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	req = j.Request
+
+	start := time.Now()
+	out, err := j.run(ctx, func(input string) (*generation, error) {
+		var video gemini_api.Video
+		var text string
+		if input == config.InputTranscript {
+			t, err := clipTranscript(ctx, req, j.languages)
+			if err != nil {
+				return nil, err
+			}
+			text = t.Text()
+		} else {
+			video = videoOf(req, j.opts)
+		}
+		return fromResponse(gemini_api.GenerateStatements(ctx, video, text, req.Model, gemini_api.GetAPIVersion(), generateConfig(j.opts)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	statements, err := parseStatements(out.text)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("summarizer.statements", len(statements)))
+
+	return &Extraction{
+		URL:        req.URL,
+		Model:      req.Model,
+		Statements: statements,
+		Input:      j.input,
+		Fallback:   j.fallback,
+		Latency:    time.Since(start),
+		Usage:      out.usage,
+	}, nil
+}
+
+// parseStatements decodes the model's JSON answer. Unknown kinds and empty
+// statements are dropped, confidences clamped to 0-1 and unreadable
+// timestamps left at zero, so one sloppy entry does not lose the rest.
+func parseStatements(answer string) ([]Statement, error) {
+	var raw []struct {
+		Kind       string  `json:"kind"`
+		Text       string  `json:"text"`
+		Speaker    string  `json:"speaker"`
+		Timestamp  string  `json:"timestamp"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(answer), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted statements: %w", err)
+	}
+
+	statements := make([]Statement, 0, len(raw))
+	for _, r := range raw {
+		kind := strings.ToLower(strings.TrimSpace(r.Kind))
+		text := strings.TrimSpace(r.Text)
+		if (kind != KindQuote && kind != KindClaim) || text == "" {
+			continue
+		}
+		at, _ := transcript.ParseTimestamp(strings.Trim(r.Timestamp, "[] "))
+		statements = append(statements, Statement{
+			Kind:       kind,
+			Text:       text,
+			Speaker:    strings.TrimSpace(r.Speaker),
+			Timestamp:  at,
+			Confidence: min(max(r.Confidence, 0), 1),
+		})
+	}
+	slices.SortStableFunc(statements, func(a, b Statement) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	return statements, nil
+}
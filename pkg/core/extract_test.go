@@ -0,0 +1,38 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseStatements(t *testing.T) {
+	answer := `[
+		{"kind": "claim", "timestamp": "12:05", "speaker": "Ada", "text": " Sales doubled in 2023. ", "confidence": 0.8},
+		{"kind": "Quote", "timestamp": "[1:02]", "speaker": "", "text": "We never ship on Fridays.", "confidence": 1.4},
+		{"kind": "opinion", "timestamp": "2:00", "speaker": "", "text": "Dropped", "confidence": 0.5},
+		{"kind": "quote", "timestamp": "later", "speaker": "Bob", "text": "Kept without a timestamp", "confidence": -1},
+		{"kind": "claim", "timestamp": "3:00", "speaker": "", "text": "  ", "confidence": 0.5}
+	]`
+	got, err := parseStatements(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Statement{
+		{Kind: KindQuote, Text: "Kept without a timestamp", Speaker: "Bob", Timestamp: 0, Confidence: 0},
+		{Kind: KindQuote, Text: "We never ship on Fridays.", Timestamp: time.Minute + 2*time.Second, Confidence: 1},
+		{Kind: KindClaim, Text: "Sales doubled in 2023.", Speaker: "Ada", Timestamp: 12*time.Minute + 5*time.Second, Confidence: 0.8},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("parseStatements() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	e := &Extraction{Statements: got}
+	if len(e.Quotes()) != 2 || len(e.Claims()) != 1 {
+		t.Errorf("Quotes() = %d, Claims() = %d", len(e.Quotes()), len(e.Claims()))
+	}
+
+	if _, err := parseStatements("Here are the quotes:"); err == nil {
+		t.Error("parseStatements() of prose should fail")
+	}
+}
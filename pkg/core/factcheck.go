@@ -0,0 +1,276 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/genai"
+)
+
+// Verdicts a fact check reaches
+const (
+	VerdictSupported  = "supported"
+	VerdictRefuted    = "refuted"
+	VerdictMixed      = "mixed"
+	VerdictUnverified = "unverified"
+)
+
+// Verdicts lists the verdicts in the order they are reported
+var Verdicts = []string{VerdictSupported, VerdictRefuted, VerdictMixed, VerdictUnverified}
+
+// Citation is a source a verdict rests on
+type Citation struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Verification is the verdict on one claim
+type Verification struct {
+	Verdict     string     `json:"verdict"`
+	Explanation string     `json:"explanation"`
+	Citations   []Citation `json:"citations,omitempty"`
+	// Grounded reports whether the verdict rests on search results rather
+	// than on the model's own knowledge
+	Grounded bool  `json:"grounded"`
+	Usage    Usage `json:"usage"`
+}
+
+// Verifier checks a claim. GeminiVerifier is the default; other sources of
+// truth, or fakes in tests, can be passed to FactCheck instead.
+type Verifier interface {
+	Verify(ctx context.Context, claim Statement) (*Verification, error)
+}
+
+// GeminiVerifier checks claims with a Gemini model, grounded in Google Search
+// results when Grounding is set. Models without the search tool fall back to
+// their own knowledge.
+type GeminiVerifier struct {
+	Model      string
+	Grounding  bool
+	Generation GenerationOptions
+}
+
+// Verify asks the model for a verdict on claim
+func (v GeminiVerifier) Verify(ctx context.Context, claim Statement) (*Verification, error) {
+	grounded := v.Grounding
+	resp, err := gemini_api.GenerateVerification(ctx, claim.Text, claim.Speaker, grounded, v.Model, gemini_api.GetAPIVersion(), generateConfig(v.Generation))
+	if err != nil && grounded && ctx.Err() == nil && metrics.ErrorClass(err) == metrics.ClassInvalidArgument {
+		slog.WarnContext(ctx, "Grounded fact check failed, checking without search", "model", v.Model, "error", err)
+		grounded = false
+		resp, err = gemini_api.GenerateVerification(ctx, claim.Text, claim.Speaker, grounded, v.Model, gemini_api.GetAPIVersion(), generateConfig(v.Generation))
+	}
+	out, err := fromResponse(resp, err)
+	if err != nil {
+		return nil, err
+	}
+	verdict, explanation := parseVerdict(out.text)
+	citations := citationsOf(resp)
+	return &Verification{
+		Verdict:     verdict,
+		Explanation: explanation,
+		Citations:   citations,
+		Grounded:    grounded && len(citations) > 0,
+		Usage:       out.usage,
+	}, nil
+}
+
+// Check is a claim together with its verification
+type Check struct {
+	Claim Statement `json:"claim"`
+	Verification
+	// Error is why the claim could not be checked; its verdict is then
+	// VerdictUnverified
+	Error string `json:"error,omitempty"`
+}
+
+// FactCheckResult is the verdicts on the claims of a video
+type FactCheckResult struct {
+	URL    string  `json:"url"`
+	Model  string  `json:"model"`
+	Checks []Check `json:"checks"`
+	// Claims is the number of claims extracted, of which at most
+	// fact_check.max_claims are checked
+	Claims int `json:"claims"`
+	// Input is what the claims were extracted from: the video or its transcript
+	Input string `json:"input"`
+	// Fallback is why the transcript was read instead of the video
+	Fallback string        `json:"fallback,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	// Usage adds up the extraction and every verification
+	Usage Usage `json:"usage"`
+}
+
+// Count returns the number of checks with the given verdict
+func (r *FactCheckResult) Count(verdict string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Verdict == verdict {
+			n++
+		}
+	}
+	return n
+}
+
+// FactCheck extracts the factual claims made in req.URL and verifies the most
+// confident of them concurrently, up to the configured fact_check.max_claims.
+// A nil verifier uses a GeminiVerifier with req's model and generation
+// settings. A claim that cannot be checked is reported as unverified with its
+// error rather than failing the others.
+func FactCheck(ctx context.Context, req Request, verifier Verifier) (result *FactCheckResult, err error) {
+	if req.Model == "" {
+		req.Model = gemini_api.GetModelName()
+	}
+	ctx, span := tracing.Start(ctx, "core.FactCheck",
+		attribute.String("summarizer.url", req.URL),
+		attribute.String("gen_ai.request.model", req.Model),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	cfg := currentConfig()
+	if verifier == nil {
+		verifier = GeminiVerifier{Model: req.Model, Grounding: cfg.FactCheck.Grounding, Generation: cfg.Generation.Merge(req.Generation)}
+	}
+
+	start := time.Now()
+	extraction, err := Extract(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	claims := extraction.Claims()
+	checked := selectClaims(claims, cfg.FactCheck.MaxClaims)
+	span.SetAttributes(attribute.Int("summarizer.claims", len(claims)), attribute.Int("summarizer.checked_claims", len(checked)))
+
+	checks := make([]Check, len(checked))
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(cfg.FactCheck.Concurrency, 1))
+	)
+	for i, claim := range checked {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			checks[i] = verify(ctx, verifier, claim)
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var verified Usage
+	for _, c := range checks {
+		verified.add(c.Usage)
+		outcome := c.Verdict
+		if c.Error != "" {
+			outcome = "error"
+		}
+		metrics.FactChecks.WithLabelValues(req.Model, outcome).Inc()
+	}
+	metrics.Tokens.WithLabelValues(req.Model, "prompt").Add(float64(verified.PromptTokens))
+	metrics.Tokens.WithLabelValues(req.Model, "output").Add(float64(verified.OutputTokens))
+	metrics.Tokens.WithLabelValues(req.Model, "thoughts").Add(float64(verified.ThoughtsTokens))
+	usage := extraction.Usage
+	usage.add(verified)
+
+	return &FactCheckResult{
+		URL:      req.URL,
+		Model:    req.Model,
+		Checks:   checks,
+		Claims:   len(claims),
+		Input:    extraction.Input,
+		Fallback: extraction.Fallback,
+		Latency:  time.Since(start),
+		Usage:    usage,
+	}, nil
+}
+
+// verify checks one claim, turning a failure into an unverified check
+func verify(ctx context.Context, verifier Verifier, claim Statement) Check {
+	v, err := verifier.Verify(ctx, claim)
+	if err != nil {
+		slog.WarnContext(ctx, "Fact check failed", "claim", claim.Text, "error", err)
+		return Check{Claim: claim, Verification: Verification{Verdict: VerdictUnverified}, Error: err.Error()}
+	}
+	if !slices.Contains(Verdicts, v.Verdict) {
+		v.Verdict = VerdictUnverified
+	}
+	return Check{Claim: claim, Verification: *v}
+}
+
+// selectClaims keeps the n most confident claims, in video order
+func selectClaims(claims []Statement, n int) []Statement {
+	if len(claims) <= n {
+		return claims
+	}
+	selected := slices.Clone(claims)
+	slices.SortStableFunc(selected, func(a, b Statement) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	selected = selected[:n]
+	slices.SortStableFunc(selected, func(a, b Statement) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	return selected
+}
+
+// verdictLine matches the "Verdict: ..." line VerifyPrompt asks for, allowing
+// for Markdown emphasis around it
+var verdictLine = regexp.MustCompile(`(?im)^[\s*_#>-]*verdict[\s*_]*:[\s*_]*([a-z-]+)[\s*_.]*$`)
+
+// verdictWords maps the words models answer with to verdicts
+var verdictWords = map[string]string{
+	"supported":   VerdictSupported,
+	"true":        VerdictSupported,
+	"correct":     VerdictSupported,
+	"refuted":     VerdictRefuted,
+	"false":       VerdictRefuted,
+	"incorrect":   VerdictRefuted,
+	"mixed":       VerdictMixed,
+	"partly-true": VerdictMixed,
+	"misleading":  VerdictMixed,
+	"unverified":  VerdictUnverified,
+}
+
+// parseVerdict splits a verification answer into its verdict and explanation.
+// An answer without a recognizable verdict line is unverified.
+func parseVerdict(answer string) (verdict, explanation string) {
+	m := verdictLine.FindStringSubmatchIndex(answer)
+	if m == nil {
+		return VerdictUnverified, strings.TrimSpace(answer)
+	}
+	verdict = cmp.Or(verdictWords[strings.ToLower(answer[m[2]:m[3]])], VerdictUnverified)
+	explanation = strings.TrimSpace(answer[:m[0]] + answer[m[1]:])
+	return verdict, explanation
+}
+
+// citationsOf lists the web sources a grounded response searched, once each
+func citationsOf(resp *genai.GenerateContentResponse) []Citation {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].GroundingMetadata == nil {
+		return nil
+	}
+	var citations []Citation
+	seen := map[string]bool{}
+	for _, chunk := range resp.Candidates[0].GroundingMetadata.GroundingChunks {
+		if chunk == nil || chunk.Web == nil || chunk.Web.URI == "" || seen[chunk.Web.URI] {
+			continue
+		}
+		seen[chunk.Web.URI] = true
+		citations = append(citations, Citation{
+			Title: cmp.Or(chunk.Web.Title, chunk.Web.Domain, chunk.Web.URI),
+			URL:   chunk.Web.URI,
+		})
+	}
+	return citations
+}
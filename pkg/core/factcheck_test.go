@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		answer, verdict, explanation string
+	}{
+		{"Verdict: supported\nThe figure matches the 2023 report.", VerdictSupported, "The figure matches the 2023 report."},
+		{"**Verdict:** Refuted.\n\nSales fell.", VerdictRefuted, "Sales fell."},
+		{"Some preamble.\nVERDICT: false\nIt was 1986.", VerdictRefuted, "Some preamble.\n\nIt was 1986."},
+		{"Verdict: misleading\nOnly in the UK.", VerdictMixed, "Only in the UK."},
+		{"Verdict: probably\nHard to say.", VerdictUnverified, "Hard to say."},
+		{"I could not find anything.", VerdictUnverified, "I could not find anything."},
+	}
+	for _, tt := range tests {
+		verdict, explanation := parseVerdict(tt.answer)
+		if verdict != tt.verdict || explanation != tt.explanation {
+			t.Errorf("parseVerdict(%q) = %q, %q, want %q, %q", tt.answer, verdict, explanation, tt.verdict, tt.explanation)
+		}
+	}
+}
+
+func TestCitationsOf(t *testing.T) {
+	resp := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		GroundingMetadata: &genai.GroundingMetadata{GroundingChunks: []*genai.GroundingChunk{
+			{Web: &genai.GroundingChunkWeb{URI: "https://a.example", Title: "A report"}},
+			{Web: &genai.GroundingChunkWeb{URI: "https://b.example", Domain: "b.example"}},
+			{Web: &genai.GroundingChunkWeb{URI: "https://a.example", Title: "A report"}},
+			{RetrievedContext: &genai.GroundingChunkRetrievedContext{URI: "gs://bucket/doc"}},
+		}},
+	}}}
+	want := []Citation{{"A report", "https://a.example"}, {"b.example", "https://b.example"}}
+	if got := citationsOf(resp); !slices.Equal(got, want) {
+		t.Errorf("citationsOf() = %+v, want %+v", got, want)
+	}
+	if got := citationsOf(&genai.GenerateContentResponse{}); got != nil {
+		t.Errorf("citationsOf() without grounding = %+v", got)
+	}
+}
+
+func TestSelectClaims(t *testing.T) {
+	claims := []Statement{
+		{Text: "a", Timestamp: 1 * time.Minute, Confidence: 0.2},
+		{Text: "b", Timestamp: 2 * time.Minute, Confidence: 0.9},
+		{Text: "c", Timestamp: 3 * time.Minute, Confidence: 0.5},
+		{Text: "d", Timestamp: 4 * time.Minute, Confidence: 0.9},
+	}
+	var got []string
+	for _, c := range selectClaims(claims, 3) {
+		got = append(got, c.Text)
+	}
+	if want := []string{"b", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("selectClaims() = %v, want %v", got, want)
+	}
+	if got := selectClaims(claims, 10); len(got) != 4 {
+		t.Errorf("selectClaims() under the limit = %d claims", len(got))
+	}
+}
+
+type verifierFunc func(context.Context, Statement) (*Verification, error)
+
+func (f verifierFunc) Verify(ctx context.Context, claim Statement) (*Verification, error) {
+	return f(ctx, claim)
+}
+
+func TestVerify(t *testing.T) {
+	claim := Statement{Kind: KindClaim, Text: "The moon is made of cheese."}
+	failing := verifierFunc(func(context.Context, Statement) (*Verification, error) {
+		return nil, errors.New("quota exceeded")
+	})
+	if c := verify(context.Background(), failing, claim); c.Verdict != VerdictUnverified || c.Error != "quota exceeded" || c.Claim != claim {
+		t.Errorf("verify() of a failing verifier = %+v", c)
+	}
+
+	odd := verifierFunc(func(context.Context, Statement) (*Verification, error) {
+		return &Verification{Verdict: "cheesy", Explanation: "No."}, nil
+	})
+	if c := verify(context.Background(), odd, claim); c.Verdict != VerdictUnverified || c.Explanation != "No." || c.Error != "" {
+		t.Errorf("verify() of an unknown verdict = %+v", c)
+	}
+}
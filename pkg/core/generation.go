@@ -0,0 +1,57 @@
+package core
+
+import (
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"google.golang.org/genai"
+)
+
+// GenerationOptions tunes sampling, reasoning and safety for one request.
+// Unset fields fall back to the configured defaults, then to the model's own.
+type GenerationOptions = config.Generation
+
+var harmCategories = map[string]genai.HarmCategory{
+	"harassment":        genai.HarmCategoryHarassment,
+	"hate_speech":       genai.HarmCategoryHateSpeech,
+	"sexually_explicit": genai.HarmCategorySexuallyExplicit,
+	"dangerous_content": genai.HarmCategoryDangerousContent,
+	"civic_integrity":   genai.HarmCategoryCivicIntegrity,
+}
+
+var mediaResolutions = map[string]genai.MediaResolution{
+	"low":    genai.MediaResolutionLow,
+	"medium": genai.MediaResolutionMedium,
+	"high":   genai.MediaResolutionHigh,
+}
+
+// generateConfig converts generation options into a Gemini request config.
+// MaxOutputTokens and the system instruction are left for GenerateSummary to
+// default when unset.
+func generateConfig(opts GenerationOptions) *genai.GenerateContentConfig {
+	cfg := &genai.GenerateContentConfig{
+		Temperature:     opts.Temperature,
+		TopP:            opts.TopP,
+		TopK:            opts.TopK,
+		Seed:            opts.Seed,
+		MaxOutputTokens: opts.MaxOutputTokens,
+		StopSequences:   opts.StopSequences,
+		MediaResolution: mediaResolutions[opts.MediaResolution],
+	}
+	if opts.ThinkingBudget != nil {
+		cfg.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: opts.ThinkingBudget}
+	}
+
+	// Per-category thresholds win over "all"
+	for _, category := range config.SafetyCategories {
+		threshold, ok := opts.Safety[category]
+		if !ok {
+			threshold, ok = opts.Safety["all"]
+		}
+		if ok {
+			cfg.SafetySettings = append(cfg.SafetySettings, &genai.SafetySetting{
+				Category:  harmCategories[category],
+				Threshold: genai.HarmBlockThreshold(threshold),
+			})
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,20 @@
+package core
+
+import (
+	"context"
+
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+)
+
+// CheckCredentials reports whether Gemini credentials are configured, without
+// calling the API
+func CheckCredentials() error {
+	return gemini_api.CheckCredentials()
+}
+
+// CheckGemini lists the models visible to the credentials, proving that they
+// work and that Gemini is reachable. Unlike ListVideoModels it is not cached.
+func CheckGemini(ctx context.Context) error {
+	_, err := listGeminiModels(ctx)
+	return err
+}
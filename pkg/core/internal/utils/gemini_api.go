@@ -3,8 +3,20 @@ package gemini_api
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/httptransport"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/logging"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/transcript"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	genai "google.golang.org/genai"
 )
 
@@ -17,37 +29,141 @@ const (
 	ModelName = "gemini-2.5-pro-preview-05-06"
 	// MaxOutputTokens
 	MaxOutputTokens = 128_000
+
+	// RetryAttempts bounds how often a transient generation failure is tried
+	RetryAttempts = 3
+	// RetryBackoff is the wait before the first retry; later retries wait longer
+	RetryBackoff = 2 * time.Second
 )
 
-func main() {
-	fmt.Println("Paste a YouTube video URL to generate content based on it.")
-	var url string
-	fmt.Scanln(&url)
-	resp, err := GenerateWithYTVideo(url)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating content: %v\n", err)
-		os.Exit(1)
-	}
-	fmt.Println("Generated content:")
-	fmt.Println(resp)
+// Settings are provided by the host application's configuration.
+// Zero values fall back to environment variables and defaults.
+type Settings struct {
+	ModelName  string
+	APIVersion string
+	APIKey     string
+	Vertex     bool
+	Project    string
+	Location   string
+	// RetryAttempts and RetryBackoff tune the retry of transient generation
+	// failures. Zero uses RetryAttempts and RetryBackoff; a negative backoff
+	// retries immediately.
+	RetryAttempts int
+	RetryBackoff  time.Duration
 }
 
-// get model name from environment variables or use defaults
+var settings Settings
+
+// Configure replaces the active settings
+func Configure(s Settings) {
+	settings = s
+}
+
+// get model name from configuration, environment variables or use defaults
 func GetModelName() string {
+	if settings.ModelName != "" {
+		return settings.ModelName
+	}
 	if modelName := os.Getenv("MODEL_NAME"); modelName != "" {
 		return modelName
 	}
 	return ModelName
 }
 
-// get API version from environment variables or use defaults
+// get API version from configuration, environment variables or use defaults
 func GetAPIVersion() string {
+	if settings.APIVersion != "" {
+		return settings.APIVersion
+	}
 	if apiVersion := os.Getenv("API_VERSION"); apiVersion != "" {
 		return apiVersion
 	}
 	return APIVersion
 }
 
+// newClient creates a genai client using the configured credentials
+func newClient(ctx context.Context, apiVersion string) (*genai.Client, error) {
+	cc := &genai.ClientConfig{
+		HTTPOptions: genai.HTTPOptions{APIVersion: apiVersion},
+		APIKey:      settings.APIKey,
+	}
+	// Tag upstream calls so they can be matched with our own logs
+	if id := logging.RequestID(ctx); id != "" {
+		cc.HTTPOptions.Headers = http.Header{logging.RequestIDHeader: []string{id}}
+	}
+	if settings.Vertex {
+		cc.Backend = genai.BackendVertexAI
+		cc.Project = settings.Project
+		cc.Location = settings.Location
+	}
+	httpClient, err := tracedHTTPClient(ctx, cc)
+	if err != nil {
+		return nil, err
+	}
+	cc.HTTPClient = httpClient
+
+	client, err := genai.NewClient(ctx, cc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create genai client: %w", err)
+	}
+	return client, nil
+}
+
+// CheckCredentials reports whether credentials for the configured backend
+// can be found, without calling the API
+func CheckCredentials() error {
+	if !useVertex(settings.Vertex) {
+		if settings.APIKey == "" && os.Getenv("GOOGLE_API_KEY") == "" {
+			return fmt.Errorf("no Gemini API key: set GOOGLE_API_KEY or credentials.api_key_file")
+		}
+		return nil
+	}
+	if _, err := credentials.DetectDefault(&credentials.DetectOptions{
+		Scopes: []string{"https://www.googleapis.com/auth/cloud-platform"},
+	}); err != nil {
+		return fmt.Errorf("no Vertex AI credentials: %w", err)
+	}
+	return nil
+}
+
+// useVertex reports whether genai will talk to Vertex AI, which it also does
+// when GOOGLE_GENAI_USE_VERTEXAI is set
+func useVertex(configured bool) bool {
+	if configured {
+		return true
+	}
+	v := strings.ToLower(os.Getenv("GOOGLE_GENAI_USE_VERTEXAI"))
+	return v == "1" || v == "true"
+}
+
+// tracedHTTPClient returns the HTTP client genai would build for cc, with a
+// transport that records a span for every upstream call
+func tracedHTTPClient(ctx context.Context, cc *genai.ClientConfig) (*http.Client, error) {
+	if !useVertex(cc.Backend == genai.BackendVertexAI) {
+		return &http.Client{Transport: tracing.Transport(nil)}, nil
+	}
+
+	creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+		Scopes: []string{"https://www.googleapis.com/auth/cloud-platform"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find default credentials: %w", err)
+	}
+	quotaProject, err := creds.QuotaProjectID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota project ID: %w", err)
+	}
+	client, err := httptransport.NewClient(&httptransport.Options{
+		Credentials:      creds,
+		Headers:          http.Header{"X-Goog-User-Project": []string{quotaProject}},
+		BaseRoundTripper: tracing.Transport(nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	return client, nil
+}
+
 // GenerateWithYTVideo shows how to generate text using a YouTube video as input.
 func GenerateWithYTVideo(url string) (string, error) {
 	modelName := GetModelName()
@@ -57,40 +173,328 @@ func GenerateWithYTVideo(url string) (string, error) {
 
 // GenerateWithYTVideoAndModel allows specifying a custom model
 func GenerateWithYTVideoAndModel(url, modelName, apiVersion string) (string, error) {
-
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		HTTPOptions: genai.HTTPOptions{APIVersion: apiVersion},
-	})
+	resp, err := GenerateSummary(context.Background(), Video{URL: url}, modelName, apiVersion, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create genai client: %w", err)
+		return "", err
+	}
+	return resp.Text(), nil
+}
+
+// SummaryPrompt is the instruction sent alongside the video
+const SummaryPrompt = "Write a short summary of the video using Markdown. Be as information dense as possible. Be thorough. Use bullet lists to break down complex ideas. Provide space between sections. Produce an overall summary, list key sections to listen to, then add a thoughtful critique of the video. Then include a 'Further Reading' section that connects ideas, expands on them, and provide further information with links."
+
+// TranscriptPrompt introduces a transcript sent instead of the video
+const TranscriptPrompt = "The video is given as its transcript, one caption per line prefixed with its [m:ss] timestamp. Refer to sections by their timestamps. The transcript may be machine generated, so silently correct obvious recognition errors."
+
+// ClipPrompt tells the model which part of the video it is given. It is
+// formatted with the start and end timestamps.
+const ClipPrompt = "Only the part of the video from %s to %s is given. Cover just that part and give timestamps in the full video."
+
+// Video is a YouTube video as sent to Gemini
+type Video struct {
+	URL string
+	// Start and End clip the video unless both are zero; a zero End means
+	// the end of the video
+	Start, End time.Duration
+	// FPS is the frame sampling rate, nil for Gemini's default
+	FPS *float64
+}
+
+// clipped reports whether only part of the video is sent
+func (v Video) clipped() bool {
+	return v.Start != 0 || v.End != 0
+}
+
+// part references the video, with metadata only for settings that differ
+// from Gemini's defaults
+func (v Video) part() *genai.Part {
+	part := &genai.Part{FileData: &genai.FileData{FileURI: v.URL, MIMEType: "video/mp4"}}
+	if v.clipped() || v.FPS != nil {
+		part.VideoMetadata = &genai.VideoMetadata{StartOffset: v.Start, EndOffset: v.End, FPS: v.FPS}
 	}
+	return part
+}
 
+// GenerateSummary summarizes a YouTube video and returns the full response, including usage metadata
+func GenerateSummary(ctx context.Context, video Video, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
 	contents := []*genai.Content{
 		{Parts: []*genai.Part{
-			{Text: "Write a short summary of the video using Markdown. Be as information dense as possible. Be thorough. Use bullet lists to break down complex ideas. Provide space between sections. Produce an overall summary, list key sections to listen to, then add a thoughtful critique of the video. Then include a 'Further Reading' section that connects ideas, expands on them, and provide further information with links."},
-			{FileData: &genai.FileData{
-				FileURI:  url,
-				MIMEType: "video/mp4",
-			}},
+			{Text: SummaryPrompt + video.clipPrompt()},
+			video.part(),
 		}},
 	}
+	return Generate(ctx, contents, modelName, apiVersion, summaryConfig(config))
+}
+
+// clipPrompt is ClipPrompt for a clipped video, led by a blank line, and
+// empty otherwise
+func (v Video) clipPrompt() string {
+	if !v.clipped() {
+		return ""
+	}
+	until := "the end"
+	if v.End != 0 {
+		until = transcript.Timestamp(v.End)
+	}
+	return "\n\n" + fmt.Sprintf(ClipPrompt, transcript.Timestamp(v.Start), until)
+}
+
+// StatementsPrompt asks for the quotes and claims of a video
+const StatementsPrompt = "List the notable verbatim quotes and the factual claims made in the video, in the order they occur. Quotes must be word for word; do not paraphrase them. Claims state in one sentence what a speaker asserts as fact, such as figures, causes and predictions, whether or not it is true. Give each the [m:ss] timestamp where it is said and the speaker's name if it is stated or shown, otherwise leave the speaker empty. Rate your confidence from 0 to 1 that a quote is verbatim or that a claim is really made."
+
+// statementsSchema is the JSON the model answers StatementsPrompt with
+var statementsSchema = &genai.Schema{
+	Type: genai.TypeArray,
+	Items: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"kind":       {Type: genai.TypeString, Enum: []string{"quote", "claim"}},
+			"text":       {Type: genai.TypeString},
+			"speaker":    {Type: genai.TypeString, Description: "Name of the speaker, empty if unknown"},
+			"timestamp":  {Type: genai.TypeString, Description: "Where it is said, as m:ss or h:mm:ss"},
+			"confidence": {Type: genai.TypeNumber, Minimum: genai.Ptr(0.0), Maximum: genai.Ptr(1.0)},
+		},
+		Required:         []string{"kind", "text", "speaker", "timestamp", "confidence"},
+		PropertyOrdering: []string{"kind", "timestamp", "speaker", "text", "confidence"},
+	},
+}
+
+// GenerateStatements extracts quotes and claims as a JSON array matching
+// statementsSchema, from the video or, when text is set, from its transcript
+func GenerateStatements(ctx context.Context, video Video, text, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	content := &genai.Content{Parts: []*genai.Part{{Text: StatementsPrompt}}}
+	if text != "" {
+		content.Parts[0].Text += "\n\n" + TranscriptPrompt
+		content.Parts = append(content.Parts, &genai.Part{Text: text})
+	} else {
+		content.Parts[0].Text += video.clipPrompt()
+		content.Parts = append(content.Parts, video.part())
+	}
+	config = summaryConfig(config)
+	config.ResponseMIMEType = "application/json"
+	config.ResponseSchema = statementsSchema
+	return Generate(ctx, []*genai.Content{content}, modelName, apiVersion, config)
+}
+
+// VerifyPrompt asks for a verdict on a claim. It is formatted with the claim,
+// who made it and how the verdict should be reached.
+const VerifyPrompt = "Fact-check this claim made in a video%s:\n\n%s\n\n%s Start your answer with a line \"Verdict: \" followed by one word: supported if the evidence confirms the claim, refuted if it contradicts it, mixed if the claim is partly true or disputed, or unverified if there is not enough evidence. Then explain the verdict in at most three sentences, naming the evidence."
 
-	config := genai.GenerateContentConfig{
-		MaxOutputTokens: MaxOutputTokens,
-		SystemInstruction: &genai.Content{
+// GroundedVerification and UngroundedVerification tell the model what to base
+// its verdict on, with and without Google Search
+const (
+	GroundedVerification   = "Search for current, reliable sources and base your verdict on them."
+	UngroundedVerification = "Base your verdict on what you know, and answer unverified if you cannot be sure."
+)
+
+// GenerateVerification fact-checks a claim, grounded in Google Search
+// results when grounding is set. The sources searched are in the response's
+// grounding metadata.
+func GenerateVerification(ctx context.Context, claim, speaker string, grounding bool, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if speaker != "" {
+		speaker = " by " + speaker
+	}
+	how := UngroundedVerification
+	config = summaryConfig(config)
+	if grounding {
+		how = GroundedVerification
+		config.Tools = append(config.Tools, &genai.Tool{GoogleSearch: &genai.GoogleSearch{}})
+	}
+	contents := []*genai.Content{
+		{Parts: []*genai.Part{{Text: fmt.Sprintf(VerifyPrompt, speaker, claim, how)}}},
+	}
+	return Generate(ctx, contents, modelName, apiVersion, config)
+}
+
+// GenerateTranscriptSummary summarizes a video from its transcript alone
+func GenerateTranscriptSummary(ctx context.Context, text, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	contents := []*genai.Content{
+		{Parts: []*genai.Part{
+			{Text: SummaryPrompt + "\n\n" + TranscriptPrompt},
+			{Text: text},
+		}},
+	}
+	return Generate(ctx, contents, modelName, apiVersion, summaryConfig(config))
+}
+
+// SegmentPrompt asks for notes on one part of a video summarized in parts.
+// It is formatted with the part number, the number of parts and the part's
+// start and end timestamps.
+const SegmentPrompt = "This is part %d of %d of a longer video, from %s to %s. Summarize this part using Markdown: its key points, arguments, examples and data, each with the [m:ss] timestamp where it appears. Be information dense and thorough. The part summaries will be merged afterwards, so skip introductions, conclusions, critique and further reading."
+
+// MergePrompt introduces the part summaries of a video summarized in parts
+const MergePrompt = "The video was too long to summarize at once, so it was split into consecutive parts that were summarized separately. The part summaries follow in order. Merge them into one summary of the whole video following the instructions above: remove repetition, connect ideas across parts and keep the timestamps."
+
+// GenerateSegmentSummary summarizes the part of a video between video.Start
+// and video.End, from the video itself or, when text is set, from that part
+// of its transcript
+func GenerateSegmentSummary(ctx context.Context, video Video, text string, part, parts int, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	prompt := fmt.Sprintf(SegmentPrompt, part, parts, transcript.Timestamp(video.Start), transcript.Timestamp(video.End))
+	content := &genai.Content{Parts: []*genai.Part{{Text: prompt}}}
+	if text != "" {
+		content.Parts[0].Text += "\n\n" + TranscriptPrompt
+		content.Parts = append(content.Parts, &genai.Part{Text: text})
+	} else {
+		content.Parts = append(content.Parts, video.part())
+	}
+	return Generate(ctx, []*genai.Content{content}, modelName, apiVersion, summaryConfig(config))
+}
+
+// GenerateMergedSummary merges the part summaries of a video into one summary
+func GenerateMergedSummary(ctx context.Context, summaries []string, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	var b strings.Builder
+	for i, summary := range summaries {
+		fmt.Fprintf(&b, "# Part %d\n\n%s\n\n", i+1, strings.TrimSpace(summary))
+	}
+	contents := []*genai.Content{
+		{Parts: []*genai.Part{
+			{Text: SummaryPrompt + "\n\n" + MergePrompt},
+			{Text: b.String()},
+		}},
+	}
+	return Generate(ctx, contents, modelName, apiVersion, summaryConfig(config))
+}
+
+// summaryConfig defaults the output limit and the system instruction enforcing it
+func summaryConfig(config *genai.GenerateContentConfig) *genai.GenerateContentConfig {
+	if config == nil {
+		config = &genai.GenerateContentConfig{}
+	}
+	if config.MaxOutputTokens == 0 {
+		config.MaxOutputTokens = MaxOutputTokens
+	}
+	if config.SystemInstruction == nil {
+		config.SystemInstruction = &genai.Content{
 			Parts: []*genai.Part{
-				{Text: fmt.Sprintf("Keep your answer below %d tokens.", MaxOutputTokens)},
+				{Text: fmt.Sprintf("Keep your answer below %d tokens.", config.MaxOutputTokens)},
 			},
-		},
+		}
+	}
+	return config
+}
+
+// Generate sends contents to the given model, retrying transient failures
+// (rate limiting, server and network errors) with a linear backoff
+func Generate(ctx context.Context, contents []*genai.Content, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	ctx, span := tracing.Start(ctx, "gemini.Generate", attribute.String("gen_ai.request.model", modelName))
+	resp, err := generate(ctx, contents, modelName, apiVersion, config)
+	if resp != nil && resp.UsageMetadata != nil {
+		u := resp.UsageMetadata
+		span.SetAttributes(
+			attribute.Int("gen_ai.usage.input_tokens", int(u.PromptTokenCount)),
+			attribute.Int("gen_ai.usage.output_tokens", int(u.CandidatesTokenCount)),
+		)
+	}
+	tracing.End(span, err)
+	return resp, err
+}
+
+func generate(ctx context.Context, contents []*genai.Content, modelName, apiVersion string, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	client, err := newClient(ctx, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	attempts, backoff := retryPolicy()
+	resp, err := withRetry(ctx, modelName, attempts, backoff, func() (*genai.GenerateContentResponse, error) {
+		return client.Models.GenerateContent(ctx, modelName, contents, config)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []any{"model", modelName, "duration", time.Since(start)}
+	if u := resp.UsageMetadata; u != nil {
+		attrs = append(attrs, "prompt_tokens", u.PromptTokenCount, "output_tokens", u.CandidatesTokenCount, "total_tokens", u.TotalTokenCount)
+	}
+	slog.DebugContext(ctx, "gemini generate", attrs...)
+	return resp, nil
+}
+
+// retryPolicy returns the configured retry attempts and initial backoff
+func retryPolicy() (attempts int, backoff time.Duration) {
+	attempts, backoff = settings.RetryAttempts, settings.RetryBackoff
+	if attempts <= 0 {
+		attempts = RetryAttempts
+	}
+	if backoff == 0 {
+		backoff = RetryBackoff
+	}
+	return attempts, max(backoff, 0)
+}
+
+// withRetry calls generate until it succeeds, fails with an error that is not
+// transient or has been tried attempts times. The nth retry waits n*backoff.
+func withRetry(ctx context.Context, modelName string, attempts int, backoff time.Duration, generate func() (*genai.GenerateContentResponse, error)) (*genai.GenerateContentResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		resp, err := generate()
+		if err == nil {
+			return resp, nil
+		}
+
+		class := metrics.ErrorClass(err)
+		slog.DebugContext(ctx, "gemini generate failed", "model", modelName, "attempt", attempt, "class", class, "error", err)
+		span.AddEvent("attempt failed", trace.WithAttributes(attribute.Int("attempt", attempt), attribute.String("error.class", class)))
+		if attempt >= attempts || !metrics.Retryable(class) {
+			metrics.GeminiErrors.WithLabelValues("generate", class).Inc()
+			return nil, fmt.Errorf("failed to generate content: %w", err)
+		}
+		metrics.GeminiRetries.WithLabelValues("generate").Inc()
+		select {
+		case <-ctx.Done():
+			metrics.GeminiErrors.WithLabelValues("generate", metrics.ErrorClass(ctx.Err())).Inc()
+			return nil, fmt.Errorf("failed to generate content: %w", ctx.Err())
+		case <-time.After(time.Duration(attempt) * backoff):
+		}
+	}
+}
+
+// EmbedTexts returns one embedding vector per input text using the given embedding model
+func EmbedTexts(ctx context.Context, texts []string, modelName, apiVersion string) ([][]float32, error) {
+	client, err := newClient(ctx, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
 	}
 
-	resp, err := client.Models.GenerateContent(ctx, modelName, contents, &config)
+	start := time.Now()
+	resp, err := client.Models.EmbedContent(ctx, modelName, contents, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate content: %w", err)
+		metrics.GeminiErrors.WithLabelValues("embed", metrics.ErrorClass(err)).Inc()
+		return nil, fmt.Errorf("failed to embed content: %w", err)
+	}
+	slog.DebugContext(ctx, "gemini embed", "model", modelName, "texts", len(texts), "duration", time.Since(start))
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Embeddings))
+	}
+
+	vectors := make([][]float32, len(resp.Embeddings))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e.Values
 	}
+	return vectors, nil
+}
 
-	respText := resp.Text()
+// ListModels returns every base model visible to the configured credentials
+func ListModels(ctx context.Context, apiVersion string) ([]*genai.Model, error) {
+	client, err := newClient(ctx, apiVersion)
+	if err != nil {
+		return nil, err
+	}
 
-	return respText, nil
+	var models []*genai.Model
+	for m, err := range client.Models.All(ctx) {
+		if err != nil {
+			metrics.GeminiErrors.WithLabelValues("list_models", metrics.ErrorClass(err)).Inc()
+			return nil, fmt.Errorf("failed to list models: %w", err)
+		}
+		models = append(models, m)
+	}
+	return models, nil
 }
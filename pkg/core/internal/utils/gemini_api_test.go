@@ -0,0 +1,69 @@
+package gemini_api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	genai "google.golang.org/genai"
+)
+
+func TestWithRetry(t *testing.T) {
+	unavailable := genai.APIError{Code: 503, Message: "overloaded"}
+	invalid := genai.APIError{Code: 400, Message: "bad request"}
+	tests := []struct {
+		name      string
+		errs      []error
+		attempts  int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", nil, 3, 1, false},
+		{"transient then success", []error{unavailable, unavailable}, 3, 3, false},
+		{"transient exhausts attempts", []error{unavailable, unavailable, unavailable, unavailable}, 3, 3, true},
+		{"single attempt", []error{unavailable}, 1, 1, true},
+		{"permanent failure", []error{invalid}, 3, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := withRetry(context.Background(), "m", tt.attempts, time.Millisecond, func() (*genai.GenerateContentResponse, error) {
+				calls++
+				if calls <= len(tt.errs) {
+					return nil, tt.errs[calls-1]
+				}
+				return &genai.GenerateContentResponse{}, nil
+			})
+			if (err != nil) != tt.wantErr || calls != tt.wantCalls {
+				t.Errorf("withRetry() = %v after %d calls, want error %v after %d", err, calls, tt.wantErr, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := withRetry(ctx, "m", 3, time.Hour, func() (*genai.GenerateContentResponse, error) {
+		calls++
+		cancel()
+		return nil, genai.APIError{Code: 503}
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("withRetry() = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	defer Configure(settings)
+
+	Configure(Settings{})
+	if attempts, backoff := retryPolicy(); attempts != RetryAttempts || backoff != RetryBackoff {
+		t.Errorf("default retryPolicy() = %d, %v", attempts, backoff)
+	}
+	Configure(Settings{RetryAttempts: 5, RetryBackoff: -1})
+	if attempts, backoff := retryPolicy(); attempts != 5 || backoff != 0 {
+		t.Errorf("retryPolicy() = %d, %v, want 5, 0", attempts, backoff)
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/links"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// linkClient requests the links of summaries; tests point it at httptest servers
+var linkClient *http.Client
+
+// CheckLinks checks the links in a Markdown summary with the configured
+// links settings and returns it with broken links marked or removed, together
+// with the checked links. Summarize already does this when links.check is
+// set; CheckLinks is for text from elsewhere, such as stored summaries.
+func CheckLinks(ctx context.Context, markdown string) (string, []links.Link) {
+	return checkLinks(ctx, markdown, currentConfig().Links)
+}
+
+func checkLinks(ctx context.Context, markdown string, cfg config.Links) (string, []links.Link) {
+	ctx, span := tracing.Start(ctx, "core.CheckLinks")
+	defer span.End()
+
+	checker := &links.Checker{
+		Client:      linkClient,
+		Concurrency: cfg.Concurrency,
+		Timeout:     time.Duration(cfg.Timeout),
+		Titles:      cfg.Titles,
+		Broken:      cfg.Broken,
+	}
+	out, checked := checker.Process(ctx, markdown)
+
+	counts := map[links.Status]int{}
+	for _, l := range checked {
+		counts[l.Status]++
+		metrics.LinkChecks.WithLabelValues(string(l.Status)).Inc()
+	}
+	span.SetAttributes(
+		attribute.Int("summarizer.links", len(checked)),
+		attribute.Int("summarizer.broken_links", counts[links.StatusBroken]),
+	)
+	if len(checked) > 0 {
+		slog.InfoContext(ctx, "Links checked", "links", len(checked), "broken", counts[links.StatusBroken], "unknown", counts[links.StatusUnknown])
+	}
+	return out, checked
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/links"
+)
+
+func TestCheckLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/real" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	linkClient = srv.Client()
+	defer func() { linkClient = nil }()
+
+	summary := "## Further Reading\n\n- [Real](" + srv.URL + "/real)\n- [Invented](" + srv.URL + "/invented)\n"
+	cfg := config.Links{Check: true, Broken: config.BrokenLinksRemove, Timeout: config.Duration(time.Second), Concurrency: 2}
+	out, checked := checkLinks(context.Background(), summary, cfg)
+	if want := "## Further Reading\n\n- [Real](" + srv.URL + "/real)\n- Invented\n"; out != want {
+		t.Errorf("checkLinks() =\n%s\nwant\n%s", out, want)
+	}
+	if len(checked) != 2 || checked[0].Status != links.StatusOK || checked[1].Status != links.StatusBroken || checked[1].Code != http.StatusNotFound {
+		t.Errorf("checked links = %+v", checked)
+	}
+
+	cfg.Broken = config.BrokenLinksMark
+	if out, _ := checkLinks(context.Background(), summary, cfg); !strings.Contains(out, "~~[Invented]("+srv.URL+"/invented)~~ *(broken link)*") {
+		t.Errorf("checkLinks() did not mark the broken link:\n%s", out)
+	}
+}
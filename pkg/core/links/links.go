@@ -0,0 +1,382 @@
+// Package links checks the links in generated Markdown. Models invent
+// plausible URLs, particularly for "Further Reading", so a Checker requests
+// every link, marks or removes the ones that are certainly dead and can name
+// bare URLs after the title of the page they point to.
+package links
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+)
+
+// Defaults of a zero Checker
+const (
+	DefaultConcurrency = 8
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Status is the outcome of checking a link
+type Status string
+
+const (
+	// StatusOK links answered with a success or redirect
+	StatusOK Status = "ok"
+	// StatusBroken links are certainly dead: not found, gone or on a host
+	// that does not exist
+	StatusBroken Status = "broken"
+	// StatusUnknown links could not be judged, e.g. because the request timed
+	// out, the server failed or it turns bots away. They are left alone.
+	StatusUnknown Status = "unknown"
+	// StatusSkipped links point at loopback, private or link-local addresses,
+	// which the default client never connects to. They are left alone.
+	StatusSkipped Status = "skipped"
+)
+
+// ErrNonPublic is returned for links, or redirects, to addresses that are not
+// on the public internet
+var ErrNonPublic = errors.New("link points to a non-public address")
+
+// Link is a checked link
+type Link struct {
+	URL string `json:"url"`
+	// Text is the link text, empty for bare URLs
+	Text   string `json:"text,omitempty"`
+	Status Status `json:"status"`
+	// Code is the final HTTP status code, zero if no response arrived
+	Code int `json:"code,omitempty"`
+	// Title is the page title, fetched only when Checker.Titles is set
+	Title string `json:"title,omitempty"`
+	Err   string `json:"error,omitempty"`
+}
+
+// Checker checks links. The zero value uses a traced HTTP client that only
+// connects to public addresses, DefaultConcurrency, DefaultTimeout and marks
+// broken links.
+type Checker struct {
+	// Client replaces the default client. Links come from model output a
+	// video can steer, so a replacement must refuse internal addresses itself.
+	Client *http.Client
+	// Concurrency bounds the links requested at once
+	Concurrency int
+	// Timeout bounds the requests for each link
+	Timeout time.Duration
+	// Titles fetches page titles, using GET rather than HEAD
+	Titles bool
+	// Broken is config.BrokenLinksMark (the zero value's behaviour) or
+	// config.BrokenLinksRemove
+	Broken string
+}
+
+var defaultClient = &http.Client{
+	Transport: tracing.Transport(&http.Transport{
+		// No proxy: the dialer must see the address it really connects to
+		DialContext:         (&net.Dialer{Timeout: 30 * time.Second, Control: publicOnly}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}),
+	CheckRedirect: checkRedirect,
+}
+
+// nonPublic lists the special purpose ranges net.IP has no predicate for
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isPublic reports whether addr is on the public internet
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublic {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// publicOnly is a net.Dialer Control that refuses non-public addresses. It
+// runs after name resolution, so it also catches public names that resolve
+// to internal addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil || !isPublic(addr.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublic, address)
+	}
+	return nil
+}
+
+// checkRedirect checks every redirect hop before it is followed. The dialer
+// checks the addresses; this refuses other schemes and internal IP literals
+// early.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(req.URL.Hostname(), "[]")); err == nil && !isPublic(addr) {
+		return fmt.Errorf("%w: redirect to %s", ErrNonPublic, req.URL.Host)
+	}
+	return nil
+}
+
+// userAgent identifies the checker; some sites reject Go's default
+const userAgent = "Mozilla/5.0 (compatible; Summarizer link checker)"
+
+// maxTitleBytes bounds how much of a page is read looking for its title
+const maxTitleBytes = 256 << 10
+
+// urlPattern matches an http(s) URL, allowing one level of balanced
+// parentheses as in Wikipedia links
+const urlPattern = `https?://(?:[^\s()<>\[\]]|\([^\s()<>]*\))+`
+
+// linkPattern matches, in order of preference, an inline Markdown link or
+// image, an autolink and a bare URL
+var linkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(<?(` + urlPattern + `)>?(?:\s+"[^"]*")?\)|<(` + urlPattern + `)>|(` + urlPattern + `)`)
+
+// emptyItem matches a list item left empty by removing its only link
+var emptyItem = regexp.MustCompile(`(?m)^[ \t]*(?:[-*+]|\d+\.)[ \t]*[:\-–—]?[ \t]*\n`)
+
+// match is a link found in Markdown
+type match struct {
+	image bool
+	// bare is a bare URL or autolink, whose text is the URL itself
+	bare bool
+	text string
+	url  string
+	// trail is punctuation after a bare URL that is not part of it
+	trail string
+}
+
+// parse interprets a linkPattern match
+func parse(s string) match {
+	m := linkPattern.FindStringSubmatch(s)
+	switch {
+	case m[3] != "":
+		return match{image: m[1] != "", text: m[2], url: m[3]}
+	case m[4] != "":
+		return match{bare: true, url: m[4]}
+	}
+	url, trail := trimURL(m[5])
+	return match{bare: true, url: url, trail: trail}
+}
+
+// trimURL splits sentence punctuation and unbalanced closing parentheses off
+// a bare URL
+func trimURL(url string) (string, string) {
+	end := len(url)
+	for end > 0 {
+		c := url[end-1]
+		if strings.IndexByte(".,;:!?'\"*_", c) >= 0 || (c == ')' && strings.Count(url[:end], "(") < strings.Count(url[:end], ")")) {
+			end--
+			continue
+		}
+		break
+	}
+	return url[:end], url[end:]
+}
+
+// Find lists the links in markdown once each, in order of appearance. Images
+// are skipped.
+func Find(markdown string) []Link {
+	var links []Link
+	seen := map[string]bool{}
+	for _, s := range linkPattern.FindAllString(markdown, -1) {
+		m := parse(s)
+		if m.image || seen[m.url] {
+			continue
+		}
+		seen[m.url] = true
+		links = append(links, Link{URL: m.url, Text: m.text})
+	}
+	return links
+}
+
+// Process checks the links in markdown and returns it with the broken links
+// marked or removed and, if c.Titles is set, bare URLs named after their page
+// title, together with the checked links
+func (c *Checker) Process(ctx context.Context, markdown string) (string, []Link) {
+	links := c.Check(ctx, Find(markdown))
+	if len(links) == 0 {
+		return markdown, links
+	}
+	byURL := make(map[string]Link, len(links))
+	for _, l := range links {
+		byURL[l.URL] = l
+	}
+
+	removed := false
+	out := linkPattern.ReplaceAllStringFunc(markdown, func(s string) string {
+		m := parse(s)
+		l, ok := byURL[m.url]
+		if m.image || !ok {
+			return s
+		}
+		text := strings.TrimSuffix(s, m.trail)
+		switch {
+		case l.Status == StatusBroken && c.Broken == config.BrokenLinksRemove:
+			removed = true
+			if m.bare {
+				return m.trail
+			}
+			return m.text + m.trail
+		case l.Status == StatusBroken:
+			return "~~" + text + "~~ *(broken link)*" + m.trail
+		case l.Title != "" && (m.bare || m.text == "" || m.text == m.url):
+			return fmt.Sprintf("[%s](%s)", escapeText(l.Title), m.url) + m.trail
+		}
+		return s
+	})
+	if removed {
+		out = emptyItem.ReplaceAllString(out, "")
+	}
+	return out, links
+}
+
+// escapeText escapes a page title for use as link text
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+// Check requests every link concurrently and returns them with their
+// status, in the same order
+func (c *Checker) Check(ctx context.Context, links []Link) []Link {
+	checked := make([]Link, len(links))
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for i, l := range links {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			checked[i] = c.check(ctx, l)
+		}()
+	}
+	wg.Wait()
+	return checked
+}
+
+// check requests one link with HEAD, or GET when titles are wanted, retrying
+// with GET when a server does not handle HEAD
+func (c *Checker) check(ctx context.Context, l Link) Link {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := http.MethodHead
+	if c.Titles {
+		method = http.MethodGet
+	}
+	code, title, err := c.request(ctx, method, l.URL)
+	if method == http.MethodHead && ctx.Err() == nil && !errors.Is(err, ErrNonPublic) && (err != nil || code == http.StatusMethodNotAllowed || code == http.StatusForbidden || code == http.StatusNotImplemented || code == http.StatusBadRequest) {
+		code, title, err = c.request(ctx, http.MethodGet, l.URL)
+	}
+
+	l.Code, l.Status = code, classify(code, err)
+	if err != nil {
+		l.Err = err.Error()
+	}
+	if l.Status == StatusOK {
+		l.Title = title
+	}
+	return l
+}
+
+// request sends one request and returns the status code and, for HTML
+// answers to GET, the page title
+func (c *Checker) request(ctx context.Context, method, url string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,*/*;q=0.8")
+
+	client := c.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	var title string
+	if method == http.MethodGet && c.Titles && strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxTitleBytes))
+		title = pageTitle(body)
+	}
+	return resp.StatusCode, title, nil
+}
+
+// classify judges a link from its final status code or request error. Only
+// answers that certainly mean the page is gone count as broken.
+func classify(code int, err error) Status {
+	if errors.Is(err, ErrNonPublic) {
+		return StatusSkipped
+	}
+	if err != nil {
+		var dnsErr *net.DNSError
+		if (errors.As(err, &dnsErr) && dnsErr.IsNotFound) || errors.Is(err, syscall.ECONNREFUSED) {
+			return StatusBroken
+		}
+		return StatusUnknown
+	}
+	switch {
+	case code < 400:
+		return StatusOK
+	case code == http.StatusUnauthorized, code == http.StatusForbidden, code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return StatusUnknown
+	case code < 500:
+		return StatusBroken
+	}
+	return StatusUnknown
+}
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// pageTitle extracts the title of an HTML page
+func pageTitle(page []byte) string {
+	m := titlePattern.FindSubmatch(page)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+}
@@ -0,0 +1,172 @@
+package links
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+func TestFind(t *testing.T) {
+	md := `See [the docs](https://go.dev/doc/ "Go docs") and <https://pkg.go.dev>.
+- https://en.wikipedia.org/wiki/Go_(programming_language).
+- (https://example.com/a), again [docs](https://go.dev/doc/)
+- ![diagram](https://example.com/d.png)`
+	var got []string
+	for _, l := range Find(md) {
+		got = append(got, l.Text+"|"+l.URL)
+	}
+	want := []string{
+		"the docs|https://go.dev/doc/",
+		"|https://pkg.go.dev",
+		"|https://en.wikipedia.org/wiki/Go_(programming_language)",
+		"|https://example.com/a",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Find() = %q, want %q", got, want)
+	}
+}
+
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><head><title>\n  Fish &amp; Chips [2024]\n</title></head></html>"))
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "no HEAD", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/failing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "oops", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheck(t *testing.T) {
+	srv := testServer(t)
+	c := &Checker{Client: srv.Client(), Timeout: 100 * time.Millisecond}
+	links := []Link{{URL: srv.URL + "/ok"}, {URL: srv.URL + "/missing"}, {URL: srv.URL + "/gone"}, {URL: srv.URL + "/get-only"}, {URL: srv.URL + "/failing"}, {URL: srv.URL + "/slow"}}
+	var got []Status
+	for _, l := range c.Check(context.Background(), links) {
+		got = append(got, l.Status)
+	}
+	want := []Status{StatusOK, StatusBroken, StatusBroken, StatusOK, StatusUnknown, StatusUnknown}
+	if !slices.Equal(got, want) {
+		t.Errorf("Check() statuses = %v, want %v", got, want)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	srv := testServer(t)
+	md := "## Further Reading\n\n" +
+		"- [A good read](" + srv.URL + "/ok)\n" +
+		"- [Invented](" + srv.URL + "/missing): a deep dive\n" +
+		"- " + srv.URL + "/gone\n" +
+		"- <" + srv.URL + "/ok>, twice.\n" +
+		"- Flaky: " + srv.URL + "/failing.\n"
+
+	marked, links := (&Checker{Client: srv.Client()}).Process(context.Background(), md)
+	if len(links) != 4 {
+		t.Errorf("Process() checked %d links, want 4", len(links))
+	}
+	for _, want := range []string{
+		"- [A good read](" + srv.URL + "/ok)\n",
+		"- ~~[Invented](" + srv.URL + "/missing)~~ *(broken link)*: a deep dive\n",
+		"- ~~" + srv.URL + "/gone~~ *(broken link)*\n",
+		"- <" + srv.URL + "/ok>, twice.\n",
+		"- Flaky: " + srv.URL + "/failing.\n",
+	} {
+		if !strings.Contains(marked, want) {
+			t.Errorf("marked output missing %q:\n%s", want, marked)
+		}
+	}
+
+	removed, _ := (&Checker{Client: srv.Client(), Broken: config.BrokenLinksRemove, Titles: true}).Process(context.Background(), md)
+	want := "## Further Reading\n\n" +
+		"- [A good read](" + srv.URL + "/ok)\n" +
+		"- Invented: a deep dive\n" +
+		"- [Fish & Chips \\[2024\\]](" + srv.URL + "/ok), twice.\n" +
+		"- Flaky: " + srv.URL + "/failing.\n"
+	if removed != want {
+		t.Errorf("removed output =\n%s\nwant\n%s", removed, want)
+	}
+
+	if out, links := (&Checker{}).Process(context.Background(), "No links here."); out != "No links here." || len(links) != 0 {
+		t.Errorf("Process() without links = %q, %v", out, links)
+	}
+}
+
+func TestCheck_NonPublic(t *testing.T) {
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer srv.Close()
+
+	// The default client refuses loopback, so the server is never reached
+	checked := (&Checker{}).Check(context.Background(), []Link{{URL: srv.URL + "/admin"}})
+	if checked[0].Status != StatusSkipped || requested {
+		t.Errorf("Check() of a loopback link = %+v, requested = %v", checked[0], requested)
+	}
+	out, _ := (&Checker{Broken: config.BrokenLinksRemove}).Process(context.Background(), "- "+srv.URL+"/admin\n")
+	if out != "- "+srv.URL+"/admin\n" || requested {
+		t.Errorf("Process() rewrote a skipped link: %q", out)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, tt := range []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"192.168.0.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	} {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data/", "http://[::1]:8080/", "file:///etc/passwd"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if err := checkRedirect(req, []*http.Request{req}); err == nil {
+			t.Errorf("checkRedirect(%s) should refuse the hop", target)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if err := checkRedirect(req, []*http.Request{req}); err != nil {
+		t.Errorf("checkRedirect(example.com) error = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package logging sets up structured logging for the Summarizer binaries and
+// carries request IDs through contexts, so that library log lines and Gemini
+// calls can be correlated with the request that caused them.
+//
+// Library code never writes to stdout; it logs through slog.Default with the
+// request context and leaves handler setup to the host application.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// RequestIDHeader carries the request ID in HTTP requests and responses
+const RequestIDHeader = "X-Request-Id"
+
+// Formats accepted by New
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error"
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("unknown log level %q", level)
+	}
+	return l, nil
+}
+
+// New returns a logger writing to w. The JSON format uses the field names
+// Cloud Logging understands (severity, message) so Cloud Run parses it.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		h = slog.NewTextHandler(w, &slog.HandlerOptions{Level: l})
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l, ReplaceAttr: cloudLoggingAttr})
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// cloudLoggingAttr renames the built-in attributes to Cloud Logging's names
+func cloudLoggingAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		a.Key = "severity"
+		if a.Value.Any().(slog.Level) == slog.LevelWarn {
+			a.Value = slog.StringValue("WARNING")
+		}
+	case slog.MessageKey:
+		a.Key = "message"
+	}
+	return a
+}
+
+// contextHandler adds the request ID from the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,171 @@
+// Package metrics instruments the summarization pipeline with Prometheus
+// collectors.
+//
+// Everything is registered on Registry: cmd/web serves it at /metrics, and
+// the CLI can write it to a file for the node exporter's textfile collector or
+// push it to a Pushgateway when a run finishes.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/genai"
+)
+
+// Registry holds every Summarizer collector plus the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	// Summaries counts summarizations by model and outcome ("success" or "error")
+	Summaries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_summaries_total",
+		Help: "Summarizations by model and outcome.",
+	}, []string{"model", "outcome"})
+
+	// SummaryDuration observes end-to-end summarization latency by model
+	SummaryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summarizer_summary_duration_seconds",
+		Help:    "Summarization latency by model.",
+		Buckets: []float64{1, 2.5, 5, 10, 20, 30, 45, 60, 90, 120, 180, 300},
+	}, []string{"model"})
+
+	// Tokens counts tokens by model and kind ("prompt", "output" or "thoughts")
+	Tokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_tokens_total",
+		Help: "Gemini tokens consumed by model and kind.",
+	}, []string{"model", "kind"})
+
+	// MediaTokens counts the prompt tokens spent on video input by model,
+	// modality ("video" or "audio") and media resolution
+	MediaTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_media_tokens_total",
+		Help: "Gemini prompt tokens spent on video frames and audio by model, modality and media resolution.",
+	}, []string{"model", "modality", "resolution"})
+
+	// FactChecks counts checked claims by model and verdict ("supported",
+	// "refuted", "mixed", "unverified" or "error")
+	FactChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_fact_checks_total",
+		Help: "Claims fact-checked by model and verdict.",
+	}, []string{"model", "verdict"})
+
+	// LinkChecks counts the links checked in summaries by status ("ok",
+	// "broken" or "unknown")
+	LinkChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_link_checks_total",
+		Help: "Links in summaries checked, by status.",
+	}, []string{"status"})
+
+	// CacheLookups counts cache lookups by cache and result ("hit" or "miss")
+	CacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_cache_lookups_total",
+		Help: "Cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	// Transcripts counts transcript fetches by outcome ("success", "no_captions" or "error")
+	Transcripts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_transcript_fetches_total",
+		Help: "Transcript fetches by outcome.",
+	}, []string{"outcome"})
+
+	// TranscriptFallbacks counts video summaries retried from the transcript,
+	// by model and outcome ("success" or "error")
+	TranscriptFallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_transcript_fallbacks_total",
+		Help: "Failed video summaries retried from the transcript, by model and outcome.",
+	}, []string{"model", "outcome"})
+
+	// GeminiRetries counts retried Gemini calls by operation
+	GeminiRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_gemini_retries_total",
+		Help: "Gemini calls retried after a transient failure, by operation.",
+	}, []string{"operation"})
+
+	// GeminiErrors counts failed Gemini calls by operation and ErrorClass
+	GeminiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_gemini_errors_total",
+		Help: "Failed Gemini calls by operation and error class.",
+	}, []string{"operation", "class"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Summaries, SummaryDuration, Tokens, MediaTokens, FactChecks, LinkChecks, CacheLookups, Transcripts, TranscriptFallbacks, GeminiRetries, GeminiErrors,
+	)
+}
+
+// Error classes reported by ErrorClass
+const (
+	ClassCanceled        = "canceled"
+	ClassTimeout         = "timeout"
+	ClassRateLimited     = "rate_limited"
+	ClassInvalidArgument = "invalid_argument"
+	ClassPermission      = "permission"
+	ClassNotFound        = "not_found"
+	ClassServer          = "server"
+	ClassNetwork         = "network"
+	ClassOther           = "other"
+)
+
+// ErrorClass buckets an error from a Gemini call into a low-cardinality class
+func ErrorClass(err error) string {
+	var apiErr genai.APIError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ClassTimeout
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.Code == 429:
+			return ClassRateLimited
+		case apiErr.Code == 400:
+			return ClassInvalidArgument
+		case apiErr.Code == 401 || apiErr.Code == 403:
+			return ClassPermission
+		case apiErr.Code == 404:
+			return ClassNotFound
+		case apiErr.Code >= 500:
+			return ClassServer
+		}
+	case errors.As(err, &netErr):
+		return ClassNetwork
+	}
+	return ClassOther
+}
+
+// Retryable reports whether an error class is worth retrying
+func Retryable(class string) bool {
+	return class == ClassRateLimited || class == ClassServer || class == ClassNetwork
+}
+
+// WriteText writes every metric in the Prometheus text format
+func WriteText(w io.Writer) error {
+	families, err := Registry.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, f := range families {
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Push sends every metric to the Pushgateway at url under job
+func Push(ctx context.Context, url, job string) error {
+	return push.New(url, job).Gatherer(Registry).PushContext(ctx)
+}
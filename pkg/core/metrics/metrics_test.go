@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.Canceled, ClassCanceled},
+		{fmt.Errorf("generate: %w", context.DeadlineExceeded), ClassTimeout},
+		{fmt.Errorf("generate: %w", genai.APIError{Code: 429}), ClassRateLimited},
+		{genai.APIError{Code: 400}, ClassInvalidArgument},
+		{genai.APIError{Code: 403}, ClassPermission},
+		{genai.APIError{Code: 503}, ClassServer},
+		{fmt.Errorf("boom"), ClassOther},
+	}
+	for _, tt := range tests {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	if !Retryable(ClassServer) || Retryable(ClassInvalidArgument) {
+		t.Error("only transient classes should be retryable")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	Summaries.WithLabelValues("test-model", "success").Inc()
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := `summarizer_summaries_total{model="test-model",outcome="success"} 1`; !strings.Contains(buf.String(), want) {
+		t.Errorf("WriteText() output missing %q", want)
+	}
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gemini_api "github.com/BrunodsLilly/Summarizer/pkg/core/internal/utils"
+	"github.com/BrunodsLilly/Summarizer/pkg/core/metrics"
+)
+
+// ModelInfo describes a model that can be offered to users
+type ModelInfo struct {
+	Name             string `json:"name"`
+	DisplayName      string `json:"display_name"`
+	InputTokenLimit  int32  `json:"input_token_limit"`
+	OutputTokenLimit int32  `json:"output_token_limit"`
+	SupportsVideo    bool   `json:"supports_video"`
+}
+
+// StaticModels is the catalog used when the Models.List endpoint is unavailable
+var StaticModels = []ModelInfo{
+	{Name: "gemini-2.5-pro-preview-05-06", DisplayName: "Gemini 2.5 Pro Preview", InputTokenLimit: 1_048_576, OutputTokenLimit: 65_536, SupportsVideo: true},
+	{Name: "gemini-2.5-flash-preview-05-20", DisplayName: "Gemini 2.5 Flash Preview", InputTokenLimit: 1_048_576, OutputTokenLimit: 65_536, SupportsVideo: true},
+	{Name: "gemini-2.0-flash", DisplayName: "Gemini 2.0 Flash", InputTokenLimit: 1_048_576, OutputTokenLimit: 8_192, SupportsVideo: true},
+}
+
+const (
+	// modelCacheTTL is how long a successful model listing is reused
+	modelCacheTTL = time.Hour
+	// modelRetryInterval is how long the static fallback is used after a failed listing
+	modelRetryInterval = 5 * time.Minute
+)
+
+// Registry lists available models, caching the upstream catalog and falling
+// back to StaticModels when it cannot be fetched
+type Registry struct {
+	mu        sync.Mutex
+	list      func(ctx context.Context) ([]ModelInfo, error)
+	ttl       time.Duration
+	models    []ModelInfo
+	expiresAt time.Time
+	now       func() time.Time
+	// refreshing is closed when the listing in flight finishes; nil when
+	// there is none
+	refreshing chan struct{}
+}
+
+// NewRegistry returns a registry backed by the Gemini Models.List endpoint
+func NewRegistry() *Registry {
+	return newRegistry(listGeminiModels)
+}
+
+func newRegistry(list func(ctx context.Context) ([]ModelInfo, error)) *Registry {
+	return &Registry{list: list, ttl: modelCacheTTL, now: time.Now}
+}
+
+// SetTTL changes how long a successful listing is cached and drops the current cache
+func (r *Registry) SetTTL(ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ttl = ttl
+	r.models = nil
+}
+
+// DefaultRegistry is shared by the package level helpers
+var DefaultRegistry = NewRegistry()
+
+// Models returns all known generative models. It never fails: when the
+// upstream catalog is unreachable the last fetched catalog is kept, or the
+// static catalog returned if there is none.
+//
+// The catalog is fetched without holding the lock. While one caller refreshes
+// an expired catalog, others are served the previous one; only the very first
+// callers wait for the listing.
+func (r *Registry) Models(ctx context.Context) []ModelInfo {
+	r.mu.Lock()
+	if r.models != nil && r.now().Before(r.expiresAt) {
+		models := r.models
+		r.mu.Unlock()
+		metrics.CacheLookups.WithLabelValues("model_catalog", "hit").Inc()
+		return models
+	}
+	metrics.CacheLookups.WithLabelValues("model_catalog", "miss").Inc()
+
+	if done := r.refreshing; done != nil {
+		stale := r.models
+		r.mu.Unlock()
+		if stale != nil {
+			return stale
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return StaticModels
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.models == nil {
+			return StaticModels
+		}
+		return r.models
+	}
+
+	done := make(chan struct{})
+	r.refreshing = done
+	r.mu.Unlock()
+
+	// The catalog is shared, so one caller giving up must not fail the listing
+	// for everyone; listGeminiModels bounds it with its own timeout
+	models, err := r.list(context.WithoutCancel(ctx))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshing = nil
+	close(done)
+	if err != nil || len(models) == 0 {
+		// Keep the last good catalog over the static one, and retry later
+		if r.models == nil {
+			r.models = StaticModels
+		}
+		r.expiresAt = r.now().Add(modelRetryInterval)
+		return r.models
+	}
+	r.models = models
+	r.expiresAt = r.now().Add(r.ttl)
+	return r.models
+}
+
+// VideoModels returns the models able to summarize videos
+func (r *Registry) VideoModels(ctx context.Context) []ModelInfo {
+	var video []ModelInfo
+	for _, m := range r.Models(ctx) {
+		if m.SupportsVideo {
+			video = append(video, m)
+		}
+	}
+	return video
+}
+
+// Lookup returns the catalog entry for name
+func (r *Registry) Lookup(ctx context.Context, name string) (ModelInfo, bool) {
+	for _, m := range r.Models(ctx) {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// ListVideoModels returns the video capable models from the default registry
+func ListVideoModels(ctx context.Context) []ModelInfo {
+	return DefaultRegistry.VideoModels(ctx)
+}
+
+// DefaultModel returns the model used when a request does not name one
+func DefaultModel() string {
+	return gemini_api.GetModelName()
+}
+
+func listGeminiModels(ctx context.Context) ([]ModelInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models, err := gemini_api.ListModels(ctx, gemini_api.GetAPIVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []ModelInfo
+	for _, m := range models {
+		if !slices.Contains(m.SupportedActions, "generateContent") {
+			continue
+		}
+		name := strings.TrimPrefix(m.Name, "models/")
+		infos = append(infos, ModelInfo{
+			Name:             name,
+			DisplayName:      m.DisplayName,
+			InputTokenLimit:  m.InputTokenLimit,
+			OutputTokenLimit: m.OutputTokenLimit,
+			SupportsVideo:    supportsVideo(name),
+		})
+	}
+	return infos, nil
+}
+
+// supportsVideo reports whether a model accepts video input. The Models API
+// does not expose input modalities, so this is inferred from the model family.
+func supportsVideo(name string) bool {
+	if !strings.HasPrefix(name, "gemini-") || strings.HasPrefix(name, "gemini-1.0") {
+		return false
+	}
+	for _, unsupported := range []string{"embedding", "tts", "image-generation", "native-audio"} {
+		if strings.Contains(name, unsupported) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry_CachesUpstreamCatalog(t *testing.T) {
+	calls := 0
+	r := newRegistry(func(context.Context) ([]ModelInfo, error) {
+		calls++
+		return []ModelInfo{{Name: "gemini-x", SupportsVideo: true}, {Name: "text-only"}}, nil
+	})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	r.Models(ctx)
+	if got := r.VideoModels(ctx); len(got) != 1 || got[0].Name != "gemini-x" {
+		t.Errorf("VideoModels() = %v, want only gemini-x", got)
+	}
+	if calls != 1 {
+		t.Errorf("list called %d times within TTL, want 1", calls)
+	}
+
+	now = now.Add(modelCacheTTL + time.Second)
+	r.Models(ctx)
+	if calls != 2 {
+		t.Errorf("list called %d times after TTL, want 2", calls)
+	}
+}
+
+func TestRegistry_ServesStaleCatalogWhileRefreshing(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	calls := 0
+	r := newRegistry(func(context.Context) ([]ModelInfo, error) {
+		calls++
+		if calls == 2 {
+			close(started)
+			<-release
+			return []ModelInfo{{Name: "new"}}, nil
+		}
+		return []ModelInfo{{Name: "old"}}, nil
+	})
+	var mu sync.Mutex
+	now := time.Now()
+	r.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	ctx := context.Background()
+	r.Models(ctx)
+	mu.Lock()
+	now = now.Add(modelCacheTTL + time.Second)
+	mu.Unlock()
+
+	refreshed := make(chan []ModelInfo)
+	go func() { refreshed <- r.Models(ctx) }()
+	<-started
+
+	// The refresh is blocked in list; other callers must not wait for it
+	if got := r.Models(ctx); len(got) != 1 || got[0].Name != "old" {
+		t.Errorf("Models() during refresh = %v, want the stale catalog", got)
+	}
+	close(release)
+	if got := <-refreshed; len(got) != 1 || got[0].Name != "new" {
+		t.Errorf("Models() that refreshed = %v, want the new catalog", got)
+	}
+	if got := r.Models(ctx); got[0].Name != "new" || calls != 2 {
+		t.Errorf("Models() after refresh = %v with %d listings, want the new catalog from 2", got, calls)
+	}
+}
+
+func TestRegistry_FallsBackToStaticCatalog(t *testing.T) {
+	r := newRegistry(func(context.Context) ([]ModelInfo, error) {
+		return nil, errors.New("unreachable")
+	})
+
+	models := r.Models(context.Background())
+	if len(models) != len(StaticModels) {
+		t.Fatalf("Models() = %v, want static catalog", models)
+	}
+	if _, ok := r.Lookup(context.Background(), "gemini-2.0-flash"); !ok {
+		t.Error("Lookup() should find static models")
+	}
+}
+
+func TestRegistry_KeepsCatalogWhenRefreshFails(t *testing.T) {
+	fail := false
+	r := newRegistry(func(ctx context.Context) ([]ModelInfo, error) {
+		if fail {
+			return nil, errors.New("unreachable")
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return []ModelInfo{{Name: "fetched"}}, nil
+	})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	// A caller that already gave up still fetches the shared catalog
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := r.Models(canceled); len(got) != 1 || got[0].Name != "fetched" {
+		t.Fatalf("Models() with a canceled context = %v, want the fetched catalog", got)
+	}
+
+	fail = true
+	now = now.Add(modelCacheTTL + time.Second)
+	if got := r.Models(context.Background()); len(got) != 1 || got[0].Name != "fetched" {
+		t.Errorf("Models() after a failed refresh = %v, want the last fetched catalog", got)
+	}
+}
+
+func TestSupportsVideo(t *testing.T) {
+	tests := map[string]bool{
+		"gemini-2.0-flash":                          true,
+		"gemini-2.5-pro-preview-05-06":              true,
+		"gemini-1.0-pro":                            false,
+		"gemini-2.5-flash-preview-tts":              false,
+		"gemini-embedding-exp":                      false,
+		"gemini-2.0-flash-preview-image-generation": false,
+		"gemma-3-27b-it":                            false,
+	}
+	for name, want := range tests {
+		if got := supportsVideo(name); got != want {
+			t.Errorf("supportsVideo(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
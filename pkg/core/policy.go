@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+// DefaultAliases map friendly names to concrete models
+var DefaultAliases = map[string]string{
+	"fast": "gemini-2.0-flash",
+	"best": "gemini-2.5-pro-preview-05-06",
+}
+
+// ModelNotAllowedError is returned when a requested model is outside the policy
+type ModelNotAllowedError struct {
+	Requested string
+	Allowed   []string
+}
+
+func (e *ModelNotAllowedError) Error() string {
+	return fmt.Sprintf("model %q is not allowed; allowed values: %s", e.Requested, strings.Join(e.Allowed, ", "))
+}
+
+// ModelPolicy decides which models a deployment may use
+type ModelPolicy struct {
+	// Default is used when no model is requested
+	Default string
+	// Allowed lists permitted models; when empty every video model in the
+	// registry is permitted
+	Allowed []string
+	// Aliases maps names such as "fast" to concrete models
+	Aliases map[string]string
+
+	Registry *Registry
+}
+
+// NewModelPolicy builds the policy configured for the deployment
+func NewModelPolicy(cfg *config.Config) *ModelPolicy {
+	p := &ModelPolicy{
+		Default:  cfg.Model,
+		Allowed:  cfg.Models.Allowed,
+		Aliases:  map[string]string{},
+		Registry: DefaultRegistry,
+	}
+	for alias, model := range DefaultAliases {
+		p.Aliases[alias] = model
+	}
+	for alias, model := range cfg.Models.Aliases {
+		p.Aliases[alias] = model
+	}
+	return p
+}
+
+// Resolve maps a requested model or alias to a permitted model name.
+// An empty request resolves to the default model.
+func (p *ModelPolicy) Resolve(ctx context.Context, requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	model := requested
+	if model == "" {
+		model = p.Default
+	}
+	if target, ok := p.Aliases[model]; ok {
+		model = target
+	}
+
+	if p.allows(ctx, model) {
+		return model, nil
+	}
+	if requested == "" {
+		return "", fmt.Errorf("default model %q is not in the allowed models", model)
+	}
+	return "", &ModelNotAllowedError{Requested: requested, Allowed: p.AllowedValues(ctx)}
+}
+
+// Models filters a catalog down to the permitted models
+func (p *ModelPolicy) Models(ctx context.Context) []ModelInfo {
+	var models []ModelInfo
+	for _, m := range p.registry().VideoModels(ctx) {
+		if p.allows(ctx, m.Name) {
+			models = append(models, m)
+		}
+	}
+	// Allowed models unknown to the catalog are still offered
+	for _, name := range p.Allowed {
+		if !slices.ContainsFunc(models, func(m ModelInfo) bool { return m.Name == name }) {
+			models = append(models, ModelInfo{Name: name, DisplayName: name, SupportsVideo: true})
+		}
+	}
+	return models
+}
+
+// AllowedValues lists every accepted model name and alias, sorted
+func (p *ModelPolicy) AllowedValues(ctx context.Context) []string {
+	var values []string
+	for _, m := range p.Models(ctx) {
+		values = append(values, m.Name)
+	}
+	for alias, model := range p.Aliases {
+		if p.allows(ctx, model) {
+			values = append(values, alias)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+func (p *ModelPolicy) allows(ctx context.Context, model string) bool {
+	if len(p.Allowed) > 0 {
+		return slices.Contains(p.Allowed, model)
+	}
+	for _, m := range p.registry().VideoModels(ctx) {
+		if m.Name == model {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ModelPolicy) registry() *Registry {
+	if p.Registry == nil {
+		return DefaultRegistry
+	}
+	return p.Registry
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/config"
+)
+
+func testPolicy(allowed ...string) *ModelPolicy {
+	registry := newRegistry(func(context.Context) ([]ModelInfo, error) {
+		return []ModelInfo{
+			{Name: "gemini-pro", SupportsVideo: true},
+			{Name: "gemini-flash", SupportsVideo: true},
+			{Name: "text-only"},
+		}, nil
+	})
+	return &ModelPolicy{
+		Default:  "gemini-pro",
+		Allowed:  allowed,
+		Aliases:  map[string]string{"fast": "gemini-flash", "best": "gemini-pro"},
+		Registry: registry,
+	}
+}
+
+func TestModelPolicy_Resolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *ModelPolicy
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{"empty uses default", testPolicy(), "", "gemini-pro", false},
+		{"alias", testPolicy(), "fast", "gemini-flash", false},
+		{"catalog model", testPolicy(), "gemini-flash", "gemini-flash", false},
+		{"non-video model rejected", testPolicy(), "text-only", "", true},
+		{"unknown model rejected", testPolicy(), "gpt-4", "", true},
+		{"allowlist restricts catalog", testPolicy("gemini-pro"), "gemini-flash", "", true},
+		{"alias to disallowed model", testPolicy("gemini-pro"), "fast", "", true},
+		{"allowlist beyond catalog", testPolicy("custom-tuned"), "custom-tuned", "custom-tuned", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Resolve(context.Background(), tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve(%q) error = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelPolicy_ErrorListsAllowedValues(t *testing.T) {
+	_, err := testPolicy("gemini-pro").Resolve(context.Background(), "gpt-4")
+
+	var notAllowed *ModelNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("Resolve() error = %v, want ModelNotAllowedError", err)
+	}
+	if want := []string{"best", "gemini-pro"}; !reflect.DeepEqual(notAllowed.Allowed, want) {
+		t.Errorf("Allowed = %v, want %v", notAllowed.Allowed, want)
+	}
+}
+
+func TestNewModelPolicy(t *testing.T) {
+	cfg := config.Default()
+	cfg.Model = "gemini-a"
+	cfg.Models.Allowed = []string{"gemini-a", "gemini-b"}
+	cfg.Models.Aliases = map[string]string{"fast": "gemini-b", "cheap": "gemini-a"}
+
+	p := NewModelPolicy(cfg)
+	if p.Default != "gemini-a" || !reflect.DeepEqual(p.Allowed, cfg.Models.Allowed) {
+		t.Errorf("NewModelPolicy() = %+v", p)
+	}
+	if p.Aliases["fast"] != "gemini-b" || p.Aliases["cheap"] != "gemini-a" || p.Aliases["best"] == "" {
+		t.Errorf("Aliases = %v", p.Aliases)
+	}
+}
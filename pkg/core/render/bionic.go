@@ -0,0 +1,81 @@
+package render
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// bionicRenderer replaces goldmark's text renderer. Code spans and blocks
+// write their own text, so code is never touched.
+type bionicRenderer struct{}
+
+func (bionicRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindText, renderBionicText)
+}
+
+// renderBionicText renders text like goldmark's HTML renderer with hard wraps
+// and XHTML enabled, bolding the start of every word
+func renderBionicText(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.Text)
+	value := n.Segment.Value(source)
+	if n.IsRaw() {
+		html.DefaultWriter.RawWrite(w, value)
+	} else {
+		writeBionic(w, value)
+	}
+	if n.HardLineBreak() || n.SoftLineBreak() {
+		_, _ = w.WriteString("<br />\n")
+	}
+	return ast.WalkContinue, nil
+}
+
+// writeBionic escapes text, wrapping the first half of each word's letters in <b>
+func writeBionic(w util.BufWriter, text []byte) {
+	for len(text) > 0 {
+		end := bytes.IndexFunc(text, unicode.IsSpace)
+		if end == 0 {
+			_, size := utf8.DecodeRune(text)
+			_, _ = w.Write(text[:size])
+			text = text[size:]
+			continue
+		}
+		if end < 0 {
+			end = len(text)
+		}
+		writeBionicWord(w, text[:end])
+		text = text[end:]
+	}
+}
+
+func writeBionicWord(w util.BufWriter, word []byte) {
+	letters := 0
+	for _, r := range string(word) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters++
+		}
+	}
+	// Entities and backslash escapes must reach the writer whole
+	if letters < 2 || bytes.ContainsAny(word, `&\`) {
+		html.DefaultWriter.Write(w, word)
+		return
+	}
+
+	split := 0
+	for i := 0; i < (letters+1)/2; i++ {
+		_, size := utf8.DecodeRune(word[split:])
+		split += size
+	}
+	_, _ = w.WriteString("<b>")
+	html.DefaultWriter.Write(w, word[:split])
+	_, _ = w.WriteString("</b>")
+	html.DefaultWriter.Write(w, word[split:])
+}
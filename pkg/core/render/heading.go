@@ -0,0 +1,52 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// idPrefix namespaces heading anchors so a heading such as "Reader content"
+// cannot clobber an element ID of the page it is embedded in
+const idPrefix = "toc-"
+
+// headingIDs generates unique, prefixed heading anchors for one document
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: map[string]bool{}}
+}
+
+// Generate slugs value, keeping letters and digits of any script, and
+// numbers repeats: "toc-notes", "toc-notes-1"
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(string(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = "heading"
+	}
+
+	id := idPrefix + slug
+	for i := 1; s.used[id]; i++ {
+		id = idPrefix + slug + "-" + strconv.Itoa(i)
+	}
+	s.used[id] = true
+	return []byte(id)
+}
+
+// Put reserves an ID set explicitly in the Markdown
+func (s *headingIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
@@ -0,0 +1,163 @@
+// Package render turns summary Markdown into sanitized HTML and computes
+// reading aids from the goldmark AST: word count, reading time, a table of
+// contents and a bionic reading variant. The web reader, exports and the CLI
+// all use it so they agree on the numbers.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// WordsPerMinute is the reading speed behind reading time estimates
+const WordsPerMinute = 200
+
+// Options selects HTML variants
+type Options struct {
+	// Bionic bolds the first half of every word outside code
+	Bionic bool
+}
+
+// Heading is an entry in a document's table of contents. ID is the anchor
+// of the rendered heading element.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+// Document is rendered Markdown with its reading aids
+type Document struct {
+	// HTML is a sanitized fragment, safe to embed in a page
+	HTML           string    `json:"html,omitempty"`
+	Words          int       `json:"words"`
+	ReadingMinutes int       `json:"reading_minutes"`
+	Headings       []Heading `json:"headings,omitempty"`
+}
+
+var (
+	plain  = newMarkdown()
+	bionic = newMarkdown(renderer.WithNodeRenderers(util.Prioritized(bionicRenderer{}, 100)))
+)
+
+func newMarkdown(opts ...renderer.Option) goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(append([]renderer.Option{
+			html.WithHardWraps(),
+			html.WithXHTML(),
+		}, opts...)...),
+	)
+}
+
+// Render converts markdown to sanitized HTML and computes its reading aids
+func Render(markdown string, opts Options) (*Document, error) {
+	md := plain
+	if opts.Bionic {
+		md = bionic
+	}
+
+	source := []byte(markdown)
+	root := parse(md, source)
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, source, root); err != nil {
+		return nil, fmt.Errorf("failed to convert markdown: %w", err)
+	}
+
+	doc := analyze(root, source)
+	doc.HTML = Sanitize(buf.String())
+	return doc, nil
+}
+
+// Analyze computes the reading aids of markdown without rendering it
+func Analyze(markdown string) *Document {
+	source := []byte(markdown)
+	return analyze(parse(plain, source), source)
+}
+
+// parse parses source with prefixed heading IDs
+func parse(md goldmark.Markdown, source []byte) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	return md.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+}
+
+// ReadingMinutes estimates how long words take to read, rounded up
+func ReadingMinutes(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// analyze walks the AST once, collecting the visible text and the headings
+func analyze(root ast.Node, source []byte) *Document {
+	doc := &Document{}
+	var content strings.Builder
+	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// Words never run across blocks, e.g. from one list item into the next
+			if n.Type() == ast.TypeBlock {
+				content.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch n := n.(type) {
+		case *ast.Heading:
+			id, _ := n.AttributeString("id")
+			idBytes, _ := id.([]byte)
+			doc.Headings = append(doc.Headings, Heading{Level: n.Level, Text: plainText(n, source), ID: string(idBytes)})
+		case *ast.Text:
+			content.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				content.WriteByte('\n')
+			}
+		case *ast.String:
+			content.Write(n.Value)
+		case *ast.AutoLink:
+			content.Write(n.Label(source))
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				content.Write(line.Value(source))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	doc.Words = len(strings.Fields(content.String()))
+	doc.ReadingMinutes = ReadingMinutes(doc.Words)
+	return doc
+}
+
+// plainText returns the text of n's inline children without markup
+func plainText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		case *ast.AutoLink:
+			b.Write(c.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
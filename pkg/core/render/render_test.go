@@ -0,0 +1,84 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	md := "# Intro to *Go*\n\nSome **bold**text and `inline code` here.\nNext line.\n\n## Details\n\n- one\n- two\n\n```\nfunc main() {}\n```\n"
+	doc := Analyze(md)
+
+	// Intro to Go | Some boldtext and inline code here. Next line. | Details | one | two | func main() {}
+	if doc.Words != 3+8+1+1+1+3 {
+		t.Errorf("Words = %d", doc.Words)
+	}
+	if doc.ReadingMinutes != 1 {
+		t.Errorf("ReadingMinutes = %d, want 1", doc.ReadingMinutes)
+	}
+	want := []Heading{{Level: 1, Text: "Intro to Go", ID: "toc-intro-to-go"}, {Level: 2, Text: "Details", ID: "toc-details"}}
+	if !reflect.DeepEqual(doc.Headings, want) {
+		t.Errorf("Headings = %+v, want %+v", doc.Headings, want)
+	}
+}
+
+func TestRender_HeadingIDs(t *testing.T) {
+	doc, err := Render("## Notes\n\n## Notes\n\n### Café *au lait*\n\n## Reader content\n\n## !!!\n", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantIDs := []string{"toc-notes", "toc-notes-1", "toc-café-au-lait", "toc-reader-content", "toc-heading"}
+	for i, h := range doc.Headings {
+		if h.ID != wantIDs[i] {
+			t.Errorf("Headings[%d].ID = %q, want %q", i, h.ID, wantIDs[i])
+		}
+		if !strings.Contains(doc.HTML, `id="`+h.ID+`"`) {
+			t.Errorf("HTML = %s\nmissing anchor %q", doc.HTML, h.ID)
+		}
+	}
+	if len(doc.Headings) != len(wantIDs) {
+		t.Errorf("got %d headings, want %d", len(doc.Headings), len(wantIDs))
+	}
+
+	// Only generated anchors survive sanitizing
+	if got := Sanitize(`<h2 id="reader-content">x</h2><p id="toc-x">y</p>`); strings.Contains(got, "id=") {
+		t.Errorf("Sanitize() kept a foreign id: %s", got)
+	}
+}
+
+func TestReadingMinutes(t *testing.T) {
+	for words, want := range map[int]int{0: 0, 1: 1, 200: 1, 201: 2, 1000: 5} {
+		if got := ReadingMinutes(words); got != want {
+			t.Errorf("ReadingMinutes(%d) = %d, want %d", words, got, want)
+		}
+	}
+}
+
+func TestRender_Bionic(t *testing.T) {
+	md := "Reading faster & better, a b.\nKeep `code` as is.\n\n```\nunchanged text\n```\n"
+	plainDoc, err := Render(md, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Render(md, Options{Bionic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<b>Read</b>ing", "<b>fas</b>ter", "&amp;", "<b>bet</b>ter,", " a b.<br/>", "<b>Ke</b>ep",
+		"<code>code</code>", "<pre><code>unchanged text\n</code></pre>",
+	} {
+		if !strings.Contains(doc.HTML, want) {
+			t.Errorf("bionic HTML = %s\nmissing %q", doc.HTML, want)
+		}
+	}
+	if doc.Words != plainDoc.Words {
+		t.Errorf("bionic Words = %d, plain %d", doc.Words, plainDoc.Words)
+	}
+	if strings.Contains(plainDoc.HTML, "<b>") {
+		t.Errorf("plain HTML has bionic markup: %s", plainDoc.HTML)
+	}
+}
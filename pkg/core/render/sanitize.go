@@ -0,0 +1,46 @@
+package render
+
+import (
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// policy allows the HTML goldmark produces for GFM Markdown and nothing else.
+// Model output is untrusted: a summary can quote a video's description or be
+// prompt-injected into emitting scripts, event handlers or javascript: links.
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr", "blockquote", "pre", "code", "em", "strong", "b", "del",
+		"h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "li",
+		"table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^`+idPrefix+`[\p{L}\p{N}-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowStandardURLs()
+
+	// Links leave the reader in a new tab without handing it window.opener,
+	// a referrer or our endorsement
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	// GFM task lists and table alignment
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowStyles("text-align").MatchingEnum("left", "center", "right").OnElements("th", "td")
+	return p
+}
+
+// Sanitize strips everything from an HTML fragment that the allowlist does not
+// permit: scripts, event handlers, styles, iframes, forms and unsafe URLs
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements is everything rendered GFM may contain
+var allowedElements = []string{
+	"a", "b", "blockquote", "br", "code", "del", "em", "h1", "h2", "h3", "h4", "h5", "h6", "hr",
+	"img", "input", "li", "ol", "p", "pre", "strong", "table", "tbody", "td", "th", "thead", "tr", "ul",
+}
+
+// assertSafe fails unless fragment contains only allowed elements, no event
+// handlers or styles, and only http, https, mailto or relative URLs
+func assertSafe(t *testing.T, fragment string) {
+	t.Helper()
+	nodes, err := nethtml.ParseFragment(strings.NewReader(fragment), &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.ElementNode {
+			if !slices.Contains(allowedElements, n.Data) {
+				t.Errorf("element <%s> survived", n.Data)
+			}
+			for _, a := range n.Attr {
+				key := strings.ToLower(a.Key)
+				switch {
+				case strings.HasPrefix(key, "on"), key == "style" && n.Data != "th" && n.Data != "td", key == "formaction":
+					t.Errorf("attribute %s=%q survived on <%s>", a.Key, a.Val, n.Data)
+				case key == "href" || key == "src":
+					if scheme, _, ok := strings.Cut(strings.ToLower(strings.TrimSpace(a.Val)), ":"); ok && !strings.ContainsAny(scheme, "/?#") &&
+						scheme != "http" && scheme != "https" && scheme != "mailto" {
+						t.Errorf("%s=%q survived on <%s>", a.Key, a.Val, n.Data)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+}
+
+func TestRender_MaliciousCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "malicious", "*.md"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no corpus files: %v", err)
+	}
+
+	// An unsafe renderer passes raw HTML through, proving the sanitizer
+	// holds even if the Markdown stage stops dropping HTML
+	unsafe := goldmark.New(goldmark.WithExtensions(extension.GFM), goldmark.WithRendererOptions(html.WithUnsafe()))
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, opts := range []Options{{}, {Bionic: true}} {
+				doc, err := Render(string(src), opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSafe(t, doc.HTML)
+			}
+
+			var raw bytes.Buffer
+			if err := unsafe.Convert(src, &raw); err != nil {
+				t.Fatal(err)
+			}
+			assertSafe(t, Sanitize(raw.String()))
+		})
+	}
+}
+
+func TestRender_SafeLinks(t *testing.T) {
+	doc, err := Render("[docs](https://example.com/docs) and [section](#intro)\n\n- [x] done\n\n| a |\n|:-:|\n| b |\n", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := doc.HTML
+	for _, want := range []string{`href="https://example.com/docs"`, `target="_blank"`, "noopener", "noreferrer", "nofollow", `href="#intro"`, `type="checkbox"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() = %s\nmissing %s", got, want)
+		}
+	}
+	if strings.Count(got, `target="_blank"`) != 1 {
+		t.Errorf("only absolute links should open a new tab: %s", got)
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNoEmbedder is returned by semantic searches on a store without an embedder
+var ErrNoEmbedder = errors.New("semantic search requires an embedder")
+
+// maxEmbedChars bounds the amount of text sent to the embedder per summary
+const maxEmbedChars = 8000
+
+// Embedder turns text into vectors for semantic search
+type Embedder interface {
+	// Name identifies the embedding model so vectors from different models are never compared
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Result is a single search hit
+type Result struct {
+	Summary
+	// Snippet is a short highlighted excerpt (full-text search only)
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score"`
+}
+
+// Query describes a search
+type Query struct {
+	Text     string
+	Semantic bool
+	Limit    int
+	// User restricts the search to one user's summaries. Empty searches every
+	// summary, for local tools that own the whole database.
+	User string
+}
+
+// Search runs a full-text or semantic query depending on q.Semantic
+func (s *Store) Search(ctx context.Context, q Query) ([]Result, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if strings.TrimSpace(q.Text) == "" {
+		return nil, nil
+	}
+	if q.Semantic {
+		return s.semanticSearch(ctx, q)
+	}
+	return s.fullTextSearch(ctx, q)
+}
+
+func (s *Store) fullTextSearch(ctx context.Context, q Query) ([]Result, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.url, s.model, s.user_id, s.title, s.created_at,
+		       snippet(summaries_fts, 1, '[', ']', '…', 24), bm25(summaries_fts)
+		FROM summaries_fts
+		JOIN summaries s ON s.id = summaries_fts.rowid
+		WHERE summaries_fts MATCH ? AND (? = '' OR s.user_id = ?)
+		ORDER BY bm25(summaries_fts)
+		LIMIT ?`, ftsQuery(q.Text), q.User, q.User, q.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		var created int64
+		if err := rows.Scan(&r.ID, &r.URL, &r.Model, &r.User, &r.Title, &created, &r.Snippet, &r.Score); err != nil {
+			return nil, fmt.Errorf("failed to read search result: %w", err)
+		}
+		r.CreatedAt = time.Unix(created, 0).UTC()
+		// bm25 is lower-is-better; flip it so higher scores rank first everywhere
+		r.Score = -r.Score
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// ftsQuery quotes every term so user input can't produce FTS5 syntax errors.
+// Terms are ANDed together and the last one is treated as a prefix.
+func ftsQuery(text string) string {
+	terms := strings.Fields(text)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	if n := len(terms); n > 0 {
+		terms[n-1] += "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+func (s *Store) semanticSearch(ctx context.Context, q Query) ([]Result, error) {
+	if s.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+
+	query, err := s.embedOne(ctx, q.Text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.id, s.url, s.model, s.user_id, s.title, s.created_at, e.vector
+		FROM embeddings e
+		JOIN summaries s ON s.id = e.summary_id
+		WHERE e.model = ? AND (? = '' OR s.user_id = ?)`, s.embedder.Name(), q.User, q.User)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		var created int64
+		var blob []byte
+		if err := rows.Scan(&r.ID, &r.URL, &r.Model, &r.User, &r.Title, &created, &blob); err != nil {
+			return nil, fmt.Errorf("failed to read embedding: %w", err)
+		}
+		r.CreatedAt = time.Unix(created, 0).UTC()
+		r.Score = cosine(query, decodeVector(blob))
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
+
+func (s *Store) embed(ctx context.Context, summary *Summary) error {
+	vector, err := s.embedOne(ctx, truncate(summary.Title+"\n\n"+summary.Content, maxEmbedChars))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO embeddings (summary_id, model, vector) VALUES (?, ?, ?)`,
+		summary.ID, s.embedder.Name(), encodeVector(vector))
+	return err
+}
+
+// embedOne embeds a single text, guarding against embedders that return the
+// wrong number of vectors
+func (s *Store) embedOne(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder %s returned %d vectors for 1 text", s.embedder.Name(), len(vectors))
+	}
+	return vectors[0], nil
+}
+
+// truncate cuts text to at most n bytes without splitting a UTF-8 character
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
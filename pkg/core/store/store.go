@@ -0,0 +1,225 @@
+// Package store persists generated summaries and makes them searchable.
+//
+// Summaries are kept in a SQLite database with an FTS5 index for full-text
+// search. When an Embedder is configured, an embedding of every saved summary
+// is stored alongside it so the library can also be searched semantically.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned when a summary does not exist
+var ErrNotFound = errors.New("summary not found")
+
+// Summary is a generated summary as persisted in the store
+type Summary struct {
+	ID    int64  `json:"id"`
+	URL   string `json:"url"`
+	Model string `json:"model"`
+	// User is who requested the summary, if known
+	User      string    `json:"user,omitempty"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store is a SQLite backed summary library
+type Store struct {
+	db       *sql.DB
+	embedder Embedder
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithEmbedder enables semantic search using the given embedder
+func WithEmbedder(e Embedder) Option {
+	return func(s *Store) {
+		s.embedder = e
+	}
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS summaries (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	url        TEXT NOT NULL,
+	model      TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	user_id    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS summaries_fts USING fts5(
+	title, content, url,
+	content='summaries', content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS summaries_ai AFTER INSERT ON summaries BEGIN
+	INSERT INTO summaries_fts(rowid, title, content, url) VALUES (new.id, new.title, new.content, new.url);
+END;
+
+CREATE TRIGGER IF NOT EXISTS summaries_ad AFTER DELETE ON summaries BEGIN
+	INSERT INTO summaries_fts(summaries_fts, rowid, title, content, url) VALUES ('delete', old.id, old.title, old.content, old.url);
+END;
+
+CREATE TABLE IF NOT EXISTS embeddings (
+	summary_id INTEGER PRIMARY KEY REFERENCES summaries(id) ON DELETE CASCADE,
+	model      TEXT NOT NULL,
+	vector     BLOB NOT NULL
+);
+`
+
+// DefaultPath returns where the database is kept unless configured otherwise:
+// $XDG_DATA_HOME/summarizer/summaries.db, or ~/.local/share/summarizer/summaries.db
+// when XDG_DATA_HOME is unset
+func DefaultPath() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "summaries.db"
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "summarizer", "summaries.db")
+}
+
+// Open opens (and creates if needed) the summary database at path
+func Open(path string, opts ...Option) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create store directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise store schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate store schema: %w", err)
+	}
+
+	s := &Store{db: db}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// migrate adds columns introduced after a database was first created
+func migrate(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('summaries')`)
+	if err != nil {
+		return err
+	}
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = true
+	}
+	rows.Close()
+
+	if !columns["user_id"] {
+		if _, err := db.Exec(`ALTER TABLE summaries ADD COLUMN user_id TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping checks that the database can be queried
+func (s *Store) Ping(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `SELECT 1 FROM summaries LIMIT 1`)
+	return err
+}
+
+// Close releases the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save persists a summary, filling in its ID, title and creation time.
+// If an embedder is configured the summary is embedded as well; an embedding
+// failure does not undo the save but is reported to the caller.
+func (s *Store) Save(ctx context.Context, summary *Summary) error {
+	if summary.Title == "" {
+		summary.Title = TitleFromMarkdown(summary.Content, summary.URL)
+	}
+	if summary.CreatedAt.IsZero() {
+		summary.CreatedAt = time.Now().UTC()
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO summaries (url, model, user_id, title, content, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		summary.URL, summary.Model, summary.User, summary.Title, summary.Content, summary.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+	summary.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to read summary id: %w", err)
+	}
+
+	if s.embedder != nil {
+		if err := s.embed(ctx, summary); err != nil {
+			return fmt.Errorf("summary %d saved without embedding: %w", summary.ID, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the summary with the given ID if it belongs to user. Summaries
+// of other users are ErrNotFound. An empty user matches every summary, for
+// local tools that own the whole database.
+func (s *Store) Get(ctx context.Context, id int64, user string) (*Summary, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, url, model, user_id, title, content, created_at FROM summaries WHERE id = ? AND (? = '' OR user_id = ?)`, id, user, user)
+
+	var summary Summary
+	var created int64
+	err := row.Scan(&summary.ID, &summary.URL, &summary.Model, &summary.User, &summary.Title, &summary.Content, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summary: %w", err)
+	}
+	summary.CreatedAt = time.Unix(created, 0).UTC()
+	return &summary, nil
+}
+
+// TitleFromMarkdown returns the first heading of a Markdown document, or
+// fallback when there is none
+func TitleFromMarkdown(markdown, fallback string) string {
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			if title := strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+				return title
+			}
+		}
+	}
+	return fallback
+}
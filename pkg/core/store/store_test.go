@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeEmbedder maps texts onto a tiny keyword space so similarity is predictable
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Name() string { return "fake" }
+
+func (fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	keywords := []string{"cat", "dog", "rocket"}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, len(keywords))
+		for j, k := range keywords {
+			v[j] = float32(strings.Count(strings.ToLower(text), k))
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func openTestStore(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"), opts...)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSaveAndGet(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	summary := &Summary{URL: "https://youtu.be/a", Model: "m", User: "alice", Content: "# Cats\n\nAll about cats."}
+	if err := s.Save(ctx, summary); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if summary.ID == 0 {
+		t.Fatal("Save() did not assign an ID")
+	}
+
+	got, err := s.Get(ctx, summary.ID, "alice")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "Cats" || got.Content != summary.Content || got.User != "alice" {
+		t.Errorf("Get() = %+v, want title Cats, original content and user", got)
+	}
+
+	if _, err := s.Get(ctx, 999, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(999) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get(ctx, summary.ID, "mallory"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of another user's summary error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get(ctx, summary.ID, ""); err != nil {
+		t.Errorf("Get() for any user error = %v", err)
+	}
+}
+
+func TestOpen_MigratesOldSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE summaries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, url TEXT NOT NULL, model TEXT NOT NULL,
+		title TEXT NOT NULL, content TEXT NOT NULL, created_at INTEGER NOT NULL)`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+	if err := s.Save(context.Background(), &Summary{URL: "u", Model: "m", User: "bob", Content: "x"}); err != nil {
+		t.Errorf("Save() on migrated store error = %v", err)
+	}
+}
+
+func TestFullTextSearch(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, summary := range []Summary{
+		{User: "alice", Content: "# Cats\n\nCats sleep most of the day."},
+		{User: "bob", Content: "# Rockets\n\nOrbital mechanics for beginners."},
+	} {
+		summary.URL, summary.Model = "u", "m"
+		if err := s.Save(ctx, &summary); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query, user string
+		want        []string
+	}{
+		{"orbital", "", []string{"Rockets"}},
+		{"slee", "", []string{"Cats"}},
+		{`cats "day`, "", []string{"Cats"}},
+		{"nothing", "", nil},
+		{"orbital", "bob", []string{"Rockets"}},
+		{"orbital", "alice", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.user, func(t *testing.T) {
+			results, err := s.Search(ctx, Query{Text: tt.query, User: tt.user})
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var titles []string
+			for _, r := range results {
+				titles = append(titles, r.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemanticSearch(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := openTestStore(t).Search(ctx, Query{Text: "cat", Semantic: true}); !errors.Is(err, ErrNoEmbedder) {
+		t.Errorf("Search() without embedder error = %v, want ErrNoEmbedder", err)
+	}
+
+	s := openTestStore(t, WithEmbedder(fakeEmbedder{}))
+	for _, content := range []string{"# Pets\n\ndog dog cat", "# Space\n\nrocket rocket"} {
+		if err := s.Save(ctx, &Summary{URL: "u", Model: "m", Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := s.Search(ctx, Query{Text: "rocket", Semantic: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Title != "Space" {
+		t.Errorf("Search() = %+v, want Space ranked first", results)
+	}
+
+	if results, err := s.Search(ctx, Query{Text: "rocket", Semantic: true, User: "alice"}); err != nil || len(results) != 0 {
+		t.Errorf("Search() for a user without summaries = %+v, %v", results, err)
+	}
+}
+
+// brokenEmbedder returns no vectors at all
+type brokenEmbedder struct{}
+
+func (brokenEmbedder) Name() string { return "broken" }
+
+func (brokenEmbedder) Embed(context.Context, []string) ([][]float32, error) { return nil, nil }
+
+func TestSemanticSearch_WrongVectorCount(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t, WithEmbedder(brokenEmbedder{}))
+
+	summary := &Summary{URL: "u", Model: "m", Content: "# Pets\n\ncat"}
+	if err := s.Save(ctx, summary); err == nil || summary.ID == 0 {
+		t.Errorf("Save() = %v with ID %d, want the summary saved and an embedding error", err, summary.ID)
+	}
+	if _, err := s.Search(ctx, Query{Text: "cat", Semantic: true}); err == nil {
+		t.Error("Search() should fail when the query gets no vector")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 3, "abc"},
+		{"héllo", 2, "h"},
+		{"日本語", 4, "日"},
+		{"日本語", 6, "日本"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.text, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	if got := DefaultPath(); got != filepath.Join("/data", "summarizer", "summaries.db") {
+		t.Errorf("DefaultPath() = %q", got)
+	}
+}
+
+func TestPing(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	s.Close()
+	if err := s.Ping(context.Background()); err == nil {
+		t.Error("Ping() on a closed store should fail")
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetup_StdoutPropagatesAcrossHTTP(t *testing.T) {
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterStdout, SampleRatio: 1, Service: "test", Stdout: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upstream := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "upstream.work")
+		End(span, errors.New("boom"))
+	}), func(*http.Request) string { return "/generate" }))
+	defer upstream.Close()
+
+	ctx, span := Start(context.Background(), "core.Summarize")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/generate", nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	End(span, nil)
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	traces := map[string]string{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var s struct {
+			Name        string
+			SpanContext struct{ TraceID string }
+			Status      struct{ Code string }
+		}
+		if err := dec.Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		traces[s.Name] = s.SpanContext.TraceID
+		if s.Name == "upstream.work" && s.Status.Code != "Error" {
+			t.Errorf("upstream.work status = %q, want Error", s.Status.Code)
+		}
+	}
+	for _, name := range []string{"core.Summarize", "HTTP GET", "GET /generate", "upstream.work"} {
+		id, ok := traces[name]
+		if !ok {
+			t.Errorf("span %q not exported; got %v", name, traces)
+		} else if id != traces["core.Summarize"] {
+			t.Errorf("span %q has trace %s, want %s", name, id, traces["core.Summarize"])
+		}
+	}
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := Setup(context.Background(), Options{Exporter: "zipkin"}); err == nil || !strings.Contains(err.Error(), "zipkin") {
+		t.Errorf("Setup() with an unknown exporter error = %v", err)
+	}
+}
@@ -0,0 +1,469 @@
+// Package transcript fetches the caption tracks of YouTube videos, both
+// uploaded and auto-generated, so a video can be summarized from its text
+// alone.
+//
+// Tracks are discovered from the player response embedded in the watch page
+// and downloaded from the timedtext endpoint in its json3 format.
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BrunodsLilly/Summarizer/pkg/core/tracing"
+)
+
+// ErrNoCaptions is returned for videos without any caption track
+var ErrNoCaptions = errors.New("video has no captions")
+
+// DefaultBaseURL is where watch pages are fetched from
+const DefaultBaseURL = "https://www.youtube.com"
+
+// maxPageSize bounds how much of a watch page or caption track is read
+const maxPageSize = 8 << 20
+
+// Track is a caption track of a video
+type Track struct {
+	// Language is a BCP 47 code such as "en" or "pt-BR"
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	// Generated tracks come from YouTube's speech recognition
+	Generated    bool `json:"generated"`
+	Translatable bool `json:"translatable"`
+
+	baseURL string
+}
+
+// Segment is a single caption cue
+type Segment struct {
+	Start    time.Duration `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Text     string        `json:"text"`
+}
+
+// Transcript is the text of a video in one language
+type Transcript struct {
+	VideoID  string        `json:"video_id"`
+	Title    string        `json:"title,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Language string        `json:"language"`
+	// Generated is set for speech recognition tracks
+	Generated bool `json:"generated"`
+	// Translated is set when YouTube machine translated the track into Language
+	Translated bool      `json:"translated"`
+	Segments   []Segment `json:"segments"`
+}
+
+// Text returns the transcript with one timestamped line per cue
+func (t *Transcript) Text() string {
+	var b strings.Builder
+	for _, s := range t.Segments {
+		fmt.Fprintf(&b, "[%s] %s\n", Timestamp(s.Start), s.Text)
+	}
+	return b.String()
+}
+
+// Between returns a copy of the transcript with the cues starting in [start, end)
+func (t *Transcript) Between(start, end time.Duration) *Transcript {
+	part := *t
+	part.Segments = nil
+	for _, s := range t.Segments {
+		if s.Start >= start && s.Start < end {
+			part.Segments = append(part.Segments, s)
+		}
+	}
+	return &part
+}
+
+// Words counts the words spoken in the transcript
+func (t *Transcript) Words() int {
+	words := 0
+	for _, s := range t.Segments {
+		words += len(strings.Fields(s.Text))
+	}
+	return words
+}
+
+// Timestamp formats d as m:ss, or h:mm:ss from an hour on
+func Timestamp(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+var hmsPattern = regexp.MustCompile(`^(\d+h)?(\d+m)?(\d+s)?$`)
+
+// ParseTimestamp parses an offset into a video: seconds ("90"), a clock
+// ("1:30" or "1:02:03") or the h/m/s form of YouTube links ("1m30s")
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) <= 3 {
+			var d time.Duration
+			for i, part := range parts {
+				n, err := strconv.ParseUint(part, 10, 32)
+				if err != nil || len(part) == 0 || (i > 0 && (len(part) != 2 || n >= 60)) {
+					return 0, fmt.Errorf("invalid timestamp %q", s)
+				}
+				d = d*60 + time.Duration(n)*time.Second
+			}
+			return d, nil
+		}
+	} else if s != "" && hmsPattern.MatchString(s) {
+		return time.ParseDuration(s)
+	}
+	return 0, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// Fetcher downloads transcripts. The zero value uses DefaultBaseURL and a
+// traced HTTP client.
+type Fetcher struct {
+	Client *http.Client
+	// BaseURL replaces DefaultBaseURL, e.g. for tests
+	BaseURL string
+}
+
+var defaultClient = &http.Client{Transport: tracing.Transport(nil), Timeout: 30 * time.Second}
+
+// Fetch downloads the transcript of videoURL with a zero Fetcher
+func Fetch(ctx context.Context, videoURL string, languages []string) (*Transcript, error) {
+	var f Fetcher
+	return f.Fetch(ctx, videoURL, languages)
+}
+
+// Fetch downloads the transcript of videoURL in the first available language
+// of languages. Uploaded captions are preferred over generated ones; when no
+// track matches, a translatable track is machine translated into the first
+// language. With no languages the video's default track is used.
+func (f *Fetcher) Fetch(ctx context.Context, videoURL string, languages []string) (*Transcript, error) {
+	ctx, span := tracing.Start(ctx, "transcript.Fetch")
+	t, err := f.fetch(ctx, videoURL, languages)
+	tracing.End(span, err)
+	return t, err
+}
+
+func (f *Fetcher) fetch(ctx context.Context, videoURL string, languages []string) (*Transcript, error) {
+	id, err := VideoID(videoURL)
+	if err != nil {
+		return nil, err
+	}
+	player, err := f.player(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tracks := player.tracks()
+	if len(tracks) == 0 {
+		return nil, ErrNoCaptions
+	}
+
+	track, translate := chooseTrack(tracks, languages)
+	u, err := url.Parse(track.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caption track URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("fmt", "json3")
+	if translate != "" {
+		q.Set("tlang", translate)
+	}
+	u.RawQuery = q.Encode()
+
+	body, err := f.get(ctx, u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download captions: %w", err)
+	}
+	segments, err := parseJSON3(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, ErrNoCaptions
+	}
+
+	t := &Transcript{
+		VideoID:   id,
+		Title:     player.VideoDetails.Title,
+		Duration:  player.duration(),
+		Language:  track.Language,
+		Generated: track.Generated,
+		Segments:  segments,
+	}
+	if translate != "" {
+		t.Language = translate
+		t.Translated = true
+	}
+	return t, nil
+}
+
+// Video describes a video and its caption tracks
+type Video struct {
+	ID       string        `json:"id"`
+	Title    string        `json:"title"`
+	Duration time.Duration `json:"duration"`
+	Tracks   []Track       `json:"tracks"`
+}
+
+// Video looks up videoURL without downloading captions
+func (f *Fetcher) Video(ctx context.Context, videoURL string) (*Video, error) {
+	id, err := VideoID(videoURL)
+	if err != nil {
+		return nil, err
+	}
+	player, err := f.player(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Video{
+		ID:       id,
+		Title:    player.VideoDetails.Title,
+		Duration: player.duration(),
+		Tracks:   player.tracks(),
+	}, nil
+}
+
+// chooseTrack picks the track for the preferred languages and, when none has
+// captions in any of them, the language to translate into
+func chooseTrack(tracks []Track, languages []string) (Track, string) {
+	for _, lang := range languages {
+		for _, generated := range []bool{false, true} {
+			for _, t := range tracks {
+				if t.Generated == generated && matchLanguage(t.Language, lang) {
+					return t, ""
+				}
+			}
+		}
+	}
+
+	best := tracks[0]
+	for _, t := range tracks {
+		if !t.Generated {
+			best = t
+			break
+		}
+	}
+	if len(languages) > 0 {
+		for _, t := range append([]Track{best}, tracks...) {
+			if t.Translatable {
+				return t, languages[0]
+			}
+		}
+	}
+	return best, ""
+}
+
+// matchLanguage reports whether a track language satisfies a requested one;
+// "en" accepts "en-GB" but "en-GB" does not accept "en-US"
+func matchLanguage(track, want string) bool {
+	track, want = strings.ToLower(track), strings.ToLower(want)
+	return track == want || strings.HasPrefix(track, want+"-")
+}
+
+// playerResponse is the part of ytInitialPlayerResponse used here
+type playerResponse struct {
+	PlayabilityStatus struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"playabilityStatus"`
+	VideoDetails struct {
+		Title         string `json:"title"`
+		LengthSeconds string `json:"lengthSeconds"`
+	} `json:"videoDetails"`
+	Captions struct {
+		Renderer struct {
+			CaptionTracks []struct {
+				BaseURL        string `json:"baseUrl"`
+				LanguageCode   string `json:"languageCode"`
+				Kind           string `json:"kind"`
+				IsTranslatable bool   `json:"isTranslatable"`
+				Name           struct {
+					SimpleText string `json:"simpleText"`
+					Runs       []struct {
+						Text string `json:"text"`
+					} `json:"runs"`
+				} `json:"name"`
+			} `json:"captionTracks"`
+		} `json:"playerCaptionsTracklistRenderer"`
+	} `json:"captions"`
+}
+
+// duration is zero for live streams and when YouTube omits the length
+func (p *playerResponse) duration() time.Duration {
+	seconds, _ := strconv.Atoi(p.VideoDetails.LengthSeconds)
+	return time.Duration(seconds) * time.Second
+}
+
+func (p *playerResponse) tracks() []Track {
+	var tracks []Track
+	for _, c := range p.Captions.Renderer.CaptionTracks {
+		name := c.Name.SimpleText
+		for _, run := range c.Name.Runs {
+			name += run.Text
+		}
+		tracks = append(tracks, Track{
+			Language:     c.LanguageCode,
+			Name:         name,
+			Generated:    c.Kind == "asr",
+			Translatable: c.IsTranslatable,
+			baseURL:      c.BaseURL,
+		})
+	}
+	return tracks
+}
+
+var playerMarker = []byte("ytInitialPlayerResponse = ")
+
+// player reads the player response embedded in the watch page of video id
+func (f *Fetcher) player(ctx context.Context, id string) (*playerResponse, error) {
+	base := f.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	page, err := f.get(ctx, base+"/watch?v="+url.QueryEscape(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watch page: %w", err)
+	}
+
+	i := bytes.Index(page, playerMarker)
+	if i < 0 {
+		return nil, errors.New("watch page has no player response")
+	}
+	var player playerResponse
+	// The decoder stops after the object, ignoring the script that follows
+	if err := json.NewDecoder(bytes.NewReader(page[i+len(playerMarker):])).Decode(&player); err != nil {
+		return nil, fmt.Errorf("failed to parse player response: %w", err)
+	}
+	if s := player.PlayabilityStatus.Status; s != "" && s != "OK" {
+		return nil, fmt.Errorf("video is not playable: %s %s", s, player.PlayabilityStatus.Reason)
+	}
+	return &player, nil
+}
+
+func (f *Fetcher) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Language", "en-US,en;q=0.8")
+	// Skips the EU cookie consent interstitial
+	req.AddCookie(&http.Cookie{Name: "CONSENT", Value: "YES+"})
+
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", req.URL.Path, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+}
+
+// parseJSON3 reads a timedtext track in json3 format. Generated tracks
+// split cues into word-timed pieces and interleave newline-only events.
+func parseJSON3(data []byte) ([]Segment, error) {
+	var track struct {
+		Events []struct {
+			StartMs    int64 `json:"tStartMs"`
+			DurationMs int64 `json:"dDurationMs"`
+			Segs       []struct {
+				UTF8 string `json:"utf8"`
+			} `json:"segs"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(data, &track); err != nil {
+		return nil, fmt.Errorf("failed to parse captions: %w", err)
+	}
+
+	var segments []Segment
+	for _, e := range track.Events {
+		var text strings.Builder
+		for _, s := range e.Segs {
+			text.WriteString(s.UTF8)
+		}
+		cue := strings.Join(strings.Fields(text.String()), " ")
+		if cue == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start:    time.Duration(e.StartMs) * time.Millisecond,
+			Duration: time.Duration(e.DurationMs) * time.Millisecond,
+			Text:     cue,
+		})
+	}
+	return segments, nil
+}
+
+var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// VideoID extracts the video ID from a YouTube URL: watch, youtu.be,
+// shorts, embed and live links are understood, as is a bare ID
+func VideoID(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if videoIDPattern.MatchString(rawURL) {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid video URL: %w", err)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	var id string
+	switch {
+	case host == "youtu.be":
+		id, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	case host == "youtube.com" || strings.HasSuffix(host, ".youtube.com") || host == "youtube-nocookie.com":
+		id = u.Query().Get("v")
+		for _, prefix := range []string{"/shorts/", "/embed/", "/live/", "/v/"} {
+			if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+				id, _, _ = strings.Cut(rest, "/")
+			}
+		}
+	}
+	if !videoIDPattern.MatchString(id) {
+		return "", fmt.Errorf("not a YouTube video URL: %q", rawURL)
+	}
+	return id, nil
+}
+
+// StartOffset returns where a video link starts playing, from its t or start
+// parameter, or 0 if it has none
+func StartOffset(rawURL string) (time.Duration, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return 0, fmt.Errorf("invalid video URL: %w", err)
+	}
+	q := u.Query()
+	t := q.Get("t")
+	if t == "" {
+		t = q.Get("start")
+	}
+	if t == "" {
+		return 0, nil
+	}
+	d, err := ParseTimestamp(t)
+	if err != nil {
+		return 0, fmt.Errorf("invalid start in video URL: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,223 @@
+package transcript
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// fakeYouTube serves the fixtures, pointing caption URLs back at itself
+func fakeYouTube(t *testing.T) (*Fetcher, *[]string) {
+	var requested []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/watch":
+			pages := map[string]string{"dQw4w9WgXcQ": "watch.html", "aaaaaaaaaaa": "no-captions.html", "bbbbbbbbbbb": "unplayable.html"}
+			page, ok := pages[r.URL.Query().Get("v")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(strings.ReplaceAll(fixture(t, page), DefaultBaseURL, srv.URL)))
+		case "/api/timedtext":
+			q := r.URL.Query()
+			requested = append(requested, q.Get("lang")+">"+q.Get("tlang"))
+			if q.Get("fmt") != "json3" || q.Get("signature") == "" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			if q.Get("kind") == "asr" {
+				w.Write([]byte(fixture(t, "asr-en.json3")))
+				return
+			}
+			w.Write([]byte(fixture(t, "manual-en-gb.json3")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &Fetcher{Client: srv.Client(), BaseURL: srv.URL}, &requested
+}
+
+func TestFetch_Languages(t *testing.T) {
+	tests := []struct {
+		languages  []string
+		want       string
+		generated  bool
+		translated bool
+		request    string
+	}{
+		{nil, "de", false, false, "de>"},
+		{[]string{"en"}, "en-GB", false, false, "en-GB>"},
+		{[]string{"fr", "de"}, "de", false, false, "de>"},
+		{[]string{"EN-gb"}, "en-GB", false, false, "en-GB>"},
+		{[]string{"ja"}, "ja", false, true, "de>ja"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.languages, ","), func(t *testing.T) {
+			f, requested := fakeYouTube(t)
+			tr, err := f.Fetch(context.Background(), "https://youtu.be/dQw4w9WgXcQ?t=42", tt.languages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tr.Language != tt.want || tr.Generated != tt.generated || tr.Translated != tt.translated {
+				t.Errorf("got %s generated=%v translated=%v", tr.Language, tr.Generated, tr.Translated)
+			}
+			if len(*requested) != 1 || (*requested)[0] != tt.request {
+				t.Errorf("caption requests = %v, want [%s]", *requested, tt.request)
+			}
+			if tr.VideoID != "dQw4w9WgXcQ" || tr.Title != "Gophers Explained" || tr.Duration != 3725*time.Second {
+				t.Errorf("video = %q %q", tr.VideoID, tr.Title)
+			}
+			if got := tr.Segments[1].Text; got != "Today: channels & select." {
+				t.Errorf("segment text = %q", got)
+			}
+		})
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	f, _ := fakeYouTube(t)
+	ctx := context.Background()
+	if _, err := f.Fetch(ctx, "https://www.youtube.com/watch?v=aaaaaaaaaaa", nil); !errors.Is(err, ErrNoCaptions) {
+		t.Errorf("no captions: %v", err)
+	}
+	if _, err := f.Fetch(ctx, "https://www.youtube.com/watch?v=bbbbbbbbbbb", nil); err == nil || !strings.Contains(err.Error(), "LOGIN_REQUIRED") {
+		t.Errorf("unplayable: %v", err)
+	}
+	if _, err := f.Fetch(ctx, "https://www.youtube.com/watch?v=ccccccccccc", nil); err == nil {
+		t.Error("missing video should fail")
+	}
+	if _, err := f.Fetch(ctx, "https://vimeo.com/123", nil); err == nil {
+		t.Error("non-YouTube URL should fail")
+	}
+}
+
+func TestVideo(t *testing.T) {
+	f, requested := fakeYouTube(t)
+	v, err := f.Video(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Title != "Gophers Explained" || v.Duration != 3725*time.Second {
+		t.Errorf("Video() = %q, %v", v.Title, v.Duration)
+	}
+	if tracks := v.Tracks; len(tracks) != 3 || tracks[0].Name != "English (auto-generated)" || !tracks[0].Generated || tracks[1].Name != "German" || tracks[1].Generated {
+		t.Errorf("Tracks = %+v", tracks)
+	}
+	if len(*requested) != 0 {
+		t.Errorf("Video() downloaded captions: %v", *requested)
+	}
+}
+
+func TestParseJSON3_Generated(t *testing.T) {
+	segments, err := parseJSON3([]byte(fixture(t, "asr-en.json3")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 {
+		t.Fatalf("got %d segments: %+v", len(segments), segments)
+	}
+	if s := segments[0]; s.Text != "welcome to the gopher show" || s.Start != 160*time.Millisecond || s.Duration != 4080*time.Millisecond {
+		t.Errorf("first segment = %+v", s)
+	}
+
+	tr := &Transcript{Segments: segments}
+	want := "[0:00] welcome to the gopher show\n[0:02] today we talk about channels\n[1:01:01] thanks for watching\n"
+	if got := tr.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if tr.Words() != 13 {
+		t.Errorf("Words() = %d, want 13", tr.Words())
+	}
+
+	if part := tr.Between(time.Second, time.Hour); len(part.Segments) != 1 || part.Segments[0].Text != "today we talk about channels" {
+		t.Errorf("Between() = %+v", part.Segments)
+	}
+	if len(tr.Segments) != 3 {
+		t.Errorf("Between() modified the transcript")
+	}
+}
+
+func TestVideoID(t *testing.T) {
+	valid := []string{
+		"dQw4w9WgXcQ",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://youtube.com/watch?feature=share&v=dQw4w9WgXcQ&t=1m",
+		"https://m.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RD",
+		"https://youtu.be/dQw4w9WgXcQ?si=abc",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ",
+		"https://www.youtube.com/embed/dQw4w9WgXcQ?start=10",
+		"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+		"https://www.youtube.com/live/dQw4w9WgXcQ",
+	}
+	for _, u := range valid {
+		if id, err := VideoID(u); err != nil || id != "dQw4w9WgXcQ" {
+			t.Errorf("VideoID(%q) = %q, %v", u, id, err)
+		}
+	}
+	for _, u := range []string{"", "https://example.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/watch?v=short", "https://www.youtube.com/channel/UCxyz"} {
+		if id, err := VideoID(u); err == nil {
+			t.Errorf("VideoID(%q) = %q, want error", u, id)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	valid := map[string]time.Duration{
+		"0":       0,
+		"90":      90 * time.Second,
+		"90s":     90 * time.Second,
+		"1m30s":   90 * time.Second,
+		"1h2m3s":  time.Hour + 2*time.Minute + 3*time.Second,
+		"40m":     40 * time.Minute,
+		"1:30":    90 * time.Second,
+		"70:00":   70 * time.Minute,
+		"1:02:03": time.Hour + 2*time.Minute + 3*time.Second,
+	}
+	for s, want := range valid {
+		if got, err := ParseTimestamp(s); err != nil || got != want {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "-5", "1.5s", "10ms", "1:5", "1:60", "1:2:3:4", ":30", "soon"} {
+		if got, err := ParseTimestamp(s); err == nil {
+			t.Errorf("ParseTimestamp(%q) = %v, want error", s, got)
+		}
+	}
+}
+
+func TestStartOffset(t *testing.T) {
+	tests := map[string]time.Duration{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":            0,
+		"https://youtube.com/watch?v=dQw4w9WgXcQ&t=2400":         40 * time.Minute,
+		"https://youtu.be/dQw4w9WgXcQ?t=1m30s":                   90 * time.Second,
+		"https://www.youtube.com/embed/dQw4w9WgXcQ?start=10":     10 * time.Second,
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1h2m3s&a": time.Hour + 2*time.Minute + 3*time.Second,
+	}
+	for u, want := range tests {
+		if got, err := StartOffset(u); err != nil || got != want {
+			t.Errorf("StartOffset(%q) = %v, %v, want %v", u, got, err, want)
+		}
+	}
+	if got, err := StartOffset("https://youtu.be/dQw4w9WgXcQ?t=later"); err == nil {
+		t.Errorf("StartOffset() = %v, want error", got)
+	}
+}